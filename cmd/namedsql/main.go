@@ -0,0 +1,270 @@
+// Command namedsql is a command line interface to the namedsql package.
+//
+// Usage:
+//
+//	namedsql render [flags] [file]
+//
+// The render subcommand reads a query from file (or from standard input if
+// file is omitted or "-"), rewrites it using ArrangeAndExpand, and prints the
+// rewritten query followed by its bindings.  Bindings are taken from a JSON
+// object (-bindings or -bindings-file) and from any number of -set name=value
+// flags, which take precedence.
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dgoffredo/namedsql/namedsql"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+const usage = `usage: namedsql render [flags] [file]
+
+Run "namedsql render -h" for a list of flags.
+`
+
+// run executes the command line args, reading from stdin and writing to
+// stdout and stderr.  It returns the process exit status: zero on success,
+// one if the query could not be rewritten, and two if the command line was
+// invalid.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "render":
+		return render(args[1:], stdin, stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "namedsql: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+}
+
+// assignments is a flag.Value that accumulates "name=value" arguments.
+type assignments []string
+
+func (values *assignments) String() string {
+	return strings.Join(*values, " ")
+}
+
+func (values *assignments) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected name=value, but got %q", value)
+	}
+	*values = append(*values, value)
+	return nil
+}
+
+// render implements the "render" subcommand.  Its parameters and return
+// value are as described for run.
+func render(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dialectName := flags.String("dialect", namedsql.Generic.Name,
+		"SQL dialect, one of: "+strings.Join(namedsql.Dialects(), ", "))
+	placeholderName := flags.String("placeholder", "",
+		"output placeholder style (question, dollar, colon, atp, named, question-number, brace, or percent); defaults to the dialect's")
+	bindingsJSON := flags.String("bindings", "", "bindings as a JSON object")
+	bindingsFile := flags.String("bindings-file", "", "file containing bindings as a JSON object")
+	format := flags.String("format", "text", "output format, text or json")
+	interpolate := flags.Bool("interpolate", false, "replace parameters with literals")
+	strict := flags.Bool("strict", false, "fail if a binding is not referred to by any parameter")
+	var sets assignments
+	flags.Var(&sets, "set",
+		"binding as name=value, where value is JSON or else a string (repeatable)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	fail := func(status int, err error) int {
+		fmt.Fprintf(stderr, "namedsql: %v\n", err)
+		return status
+	}
+
+	if flags.NArg() > 1 {
+		return fail(2, errors.New("render accepts at most one query file"))
+	}
+	if *format != "text" && *format != "json" {
+		return fail(2, fmt.Errorf("unknown output format %q", *format))
+	}
+
+	dialect, err := namedsql.LookupDialect(*dialectName)
+	if err != nil {
+		return fail(2, err)
+	}
+	if *placeholderName != "" {
+		dialect.Placeholder, err = namedsql.ParsePlaceholder(*placeholderName)
+		if err != nil {
+			return fail(2, err)
+		}
+	}
+
+	bindings, err := loadBindings(*bindingsJSON, *bindingsFile, sets)
+	if err != nil {
+		return fail(2, err)
+	}
+
+	var query []byte
+	if path := flags.Arg(0); path == "" || path == "-" {
+		query, err = io.ReadAll(stdin)
+	} else {
+		query, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fail(1, err)
+	}
+
+	options := namedsql.Options{Dialect: dialect, Interpolate: *interpolate, Strict: *strict}
+	outputQuery, outputBindings, err := options.ArrangeAndExpand(string(query), bindings)
+	if err != nil {
+		return fail(1, err)
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(struct {
+			Query    string        `json:"query"`
+			Bindings []interface{} `json:"bindings"`
+		}{outputQuery, outputBindings})
+		if err != nil {
+			return fail(1, err)
+		}
+		return 0
+	}
+
+	// The query usually ends with a newline already, e.g. from echo.
+	fmt.Fprint(stdout, outputQuery)
+	if !strings.HasSuffix(outputQuery, "\n") {
+		fmt.Fprintln(stdout)
+	}
+	for i, binding := range outputBindings {
+		fmt.Fprintf(stdout, "%d: %s\n", i+1, describe(binding))
+	}
+	return 0
+}
+
+// loadBindings returns the named bindings described by the optional JSON
+// object text bindingsJSON, the optional path bindingsFile to a file
+// containing a JSON object, and the "name=value" pairs in sets, applied in
+// that order.
+func loadBindings(bindingsJSON string, bindingsFile string, sets []string) (map[string]interface{}, error) {
+	bindings := map[string]interface{}{}
+
+	if bindingsJSON != "" {
+		if err := decodeObject([]byte(bindingsJSON), bindings); err != nil {
+			return nil, fmt.Errorf("-bindings: %v", err)
+		}
+	}
+
+	if bindingsFile != "" {
+		content, err := os.ReadFile(bindingsFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeObject(content, bindings); err != nil {
+			return nil, fmt.Errorf("%s: %v", bindingsFile, err)
+		}
+	}
+
+	for _, set := range sets {
+		name, text, _ := strings.Cut(set, "=")
+		// The value is JSON if it parses as JSON (e.g. 12 or [1, 2, 3]), and
+		// a string otherwise (e.g. purple).
+		value, err := decode([]byte(text))
+		if err != nil {
+			value = text
+		}
+		bindings[name] = value
+	}
+
+	return bindings, nil
+}
+
+// decodeObject decodes the JSON object in content into bindings.
+func decodeObject(content []byte, bindings map[string]interface{}) error {
+	value, err := decode(content)
+	if err != nil {
+		return err
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return errors.New("bindings must be a JSON object")
+	}
+
+	for name, binding := range object {
+		bindings[name] = binding
+	}
+	return nil
+}
+
+// decode returns the JSON value in content.  Integral numbers are decoded as
+// int64, so that they're printed as they were written.
+func decode(content []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return numbers(value), nil
+}
+
+// numbers returns value with every json.Number replaced by an int64, if it
+// is integral, or by a float64 otherwise.
+func numbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return integer
+		}
+		float, _ := value.Float64()
+		return float
+	case []interface{}:
+		for i, element := range value {
+			value[i] = numbers(element)
+		}
+	case map[string]interface{}:
+		for key, element := range value {
+			value[key] = numbers(element)
+		}
+	}
+	return value
+}
+
+// describe returns text showing binding and its type, e.g. `"purple"
+// (string)`.  Named bindings are prefixed by their names, e.g. `color =
+// "purple" (string)`.
+func describe(binding interface{}) string {
+	if named, ok := binding.(sql.NamedArg); ok {
+		return named.Name + " = " + describe(named.Value)
+	}
+	if text, ok := binding.(string); ok {
+		return fmt.Sprintf("%q (string)", text)
+	}
+	return fmt.Sprintf("%v (%T)", binding, binding)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// invocation is the result of calling run.
+type invocation struct {
+	status int
+	stdout string
+	stderr string
+}
+
+// invoke calls run with the specified args and standard input.
+func invoke(stdin string, args ...string) invocation {
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return invocation{status, stdout.String(), stderr.String()}
+}
+
+func TestRenderBreathing(t *testing.T) {
+	result := invoke(
+		"select * from t where x in @xs and y = :y",
+		"render", "-dialect", "postgres", "-bindings", `{"xs": [1, 2]}`, "-set", "y=foo")
+
+	if result.status != 0 {
+		t.Fatalf("unexpected exit status %d.  stderr: %s", result.status, result.stderr)
+	}
+
+	expected := "select * from t where x in ($1, $2) and y = $3\n" +
+		"1: 1 (int64)\n" +
+		"2: 2 (int64)\n" +
+		"3: \"foo\" (string)\n"
+	if result.stdout != expected {
+		t.Errorf("output not as expected.\nexpected: %q\nactual: %q", expected, result.stdout)
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	result := invoke("select :a, ?", "render", "-format", "json", "-set", "a=true")
+
+	if result.status != 1 {
+		t.Errorf("expected exit status 1 for missing positional, but got %d", result.status)
+	}
+	if !strings.Contains(result.stderr, "does not have a corresponding positional binding") {
+		t.Errorf("expected an error from arrange, but got: %q", result.stderr)
+	}
+
+	result = invoke("select :a", "render", "-format", "json", "-placeholder", "colon", "-set", "a=true")
+	expected := "{\n  \"query\": \"select :1\",\n  \"bindings\": [\n    true\n  ]\n}\n"
+	if result.status != 0 || result.stdout != expected {
+		t.Errorf("output not as expected.\nexpected: %q\nactual: %q\nstderr: %q",
+			expected, result.stdout, result.stderr)
+	}
+}
+
+func TestRenderTrailingNewline(t *testing.T) {
+	result := invoke("select :x\n", "render", "-set", "x=1")
+
+	expected := "select ?\n1: 1 (int64)\n"
+	if result.status != 0 || result.stdout != expected {
+		t.Errorf("output not as expected.\nexpected: %q\nactual: %q\nstderr: %q",
+			expected, result.stdout, result.stderr)
+	}
+}
+
+func TestRenderInterpolate(t *testing.T) {
+	result := invoke("select * from t where x in @xs and y = :y",
+		"render", "-interpolate", "-bindings", `{"xs": [1, 2.5], "y": "it's"}`)
+
+	expected := "select * from t where x in (1, 2.5) and y = 'it''s'\n"
+	if result.status != 0 || result.stdout != expected {
+		t.Errorf("output not as expected.\nexpected: %q\nactual: %q\nstderr: %q",
+			expected, result.stdout, result.stderr)
+	}
+}
+
+func TestRenderBadFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"render", "-dialect", "nonsense"},
+		{"render", "-placeholder", "nonsense"},
+		{"render", "-bindings", "[1, 2]"},
+		{"render", "-set", "novalue"},
+		{"frobnicate"},
+		{}} {
+		if result := invoke("", args...); result.status != 2 {
+			t.Errorf("expected exit status 2 for args %q, but got %d", args, result.status)
+		}
+	}
+}
+
+func TestRenderNamed(t *testing.T) {
+	result := invoke("select * from t where x = @x and y = :x", "render", "-dialect", "oracle", "-set", "x=5")
+
+	expected := "select * from t where x = :x and y = :x\n" +
+		"1: x = 5 (int64)\n"
+	if result.status != 0 || result.stdout != expected {
+		t.Errorf("output not as expected.\nexpected: %q\nactual: %q\nstderr: %q",
+			expected, result.stdout, result.stderr)
+	}
+}
@@ -1,6 +1,7 @@
 package namedsql
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 )
@@ -11,37 +12,118 @@ import (
 // bindings are looked up by name in the specified map, and positional bindings
 // are looked up by index from among the optionally specified trailing
 // arguments.
+//
+// Parts of query between "[[" and "]]" are optional fragments.  An optional
+// fragment is kept only if all of its named and explicit positional parameters
+// have non-nil bindings, and is otherwise removed from the query.
 func Arrange(query string, bindings map[string]interface{}, positionals ...interface{}) (string, []interface{}, error) {
-	tokens, positionals, err := arrange(Lex(query), bindings, positionals...)
-	return Render(tokens), positionals, err
+	return Options{}.Arrange(query, bindings, positionals...)
 }
 
-func arrange(tokens []Token, bindings map[string]interface{}, positionals ...interface{}) ([]Token, []interface{}, error) {
+func (options Options) arrange(tokens []Token, bindings map[string]interface{}, positionals ...interface{}) ([]Token, []interface{}, error) {
 	outputTokens := make([]Token, 0, len(tokens))
 	outputBindings := []interface{}{}
 	nextPositionalIndex := 0
 
-	appendParameter := func(binding interface{}) {
+	// problems are the errors encountered so far.  Unless options.AllErrors
+	// is true, we stop at the first one.
+	var problems []error
+	fail := func(problem error) bool {
+		problems = append(problems, problem)
+		return !options.AllErrors
+	}
+
+	appendParameter := func(token Token, binding interface{}) error {
 		// When we encounter a parameter in the input, we'll output a token and
-		// a binding.
-		outputTokens = append(outputTokens, Token{Kind: "implicit", Text: "?"})
+		// a binding, unless the binding is a Fragment, in which case we'll
+		// output the fragment's tokens and bindings, or an Identifier, in
+		// which case we'll output the quoted identifier and no binding.
+		switch binding := binding.(type) {
+		case Fragment:
+			if options.arranging(binding) {
+				return &RecursiveFragmentError{Parameter: token.Text, Position: Position{Offset: token.Offset}}
+			}
+			tokens, bindings, err := binding.arrange(options)
+			if err != nil {
+				return fmt.Errorf("in fragment bound to parameter %q: %w", token.Text, err)
+			}
+			outputTokens = append(outputTokens, tokens...)
+			outputBindings = append(outputBindings, bindings...)
+			return nil
+		case Identifier:
+			quoted, err := options.Dialect.quoteIdentifier(binding)
+			if err != nil {
+				return fmt.Errorf("identifier bound to parameter %q: %w", token.Text, err)
+			}
+			outputTokens = append(outputTokens, Token{
+				Kind:   QuotedIdentifier,
+				Text:   quoted,
+				Offset: token.Offset,
+				Line:   token.Line,
+				Column: token.Column})
+			return nil
+		}
+
+		if options.Dialect.Placeholder == Brace && token.Type == "" {
+			return &MissingTypeError{Parameter: token.Text, Position: Position{Offset: token.Offset}}
+		}
+
+		// The output parameter keeps the name of a named parameter, or the
+		// index of an explicit positional parameter, in case it's rendered in
+		// a style that shares bindings among occurrences (see renderShared).
+		// It also keeps the type of a typed parameter.
+		output := Token{
+			Kind:   ImplicitParameter,
+			Text:   "?",
+			Type:   token.Type,
+			Offset: token.Offset,
+			Line:   token.Line,
+			Column: token.Column}
+		if token.Kind != ImplicitParameter {
+			output.Inside = token.Inside
+		}
+		outputTokens = append(outputTokens, output)
 		outputBindings = append(outputBindings, binding)
+		return nil
 	}
 
+	declared := options.Dialect.declaredVariables(tokens)
 	for _, token := range tokens {
-		if token.Kind == "named" || token.Kind == "python" {
+		if options.Dialect.isVariable(token, bindings, declared) {
+			// It's a variable that looks like a named parameter, so forward
+			// it to the output as a word.
+			outputTokens = append(outputTokens, Token{
+				Kind:   Word,
+				Text:   token.Text,
+				Offset: token.Offset,
+				Line:   token.Line,
+				Column: token.Column})
+		} else if token.Kind == NamedParameter || token.Kind == PythonParameter {
 			// It's a named parameter.  Replace it with an implicit positional
 			// parameter, and append the appropriate binding from `bindings`.
 			name := token.Inside
 			binding, ok := bindings[name]
 			if !ok {
-				whine := fmt.Errorf(
-					"named parameter %q does not have a corresponding binding",
-					token.Text)
-				return nil, nil, whine
+				whine := &MissingBindingError{
+					Parameter: token.Text,
+					Name:      name,
+					Position:  Position{Offset: token.Offset}}
+				if fail(whine) {
+					return nil, nil, whine
+				}
+				continue
+			}
+			if options.redact != nil && options.redact.MatchString(name) {
+				switch binding.(type) {
+				case Fragment, Identifier:
+				default:
+					binding = Secret{binding}
+				}
+			}
+			if err := appendParameter(token, binding); err != nil && fail(err) {
+				return nil, nil, err
 			}
-			appendParameter(binding)
-		} else if token.Kind == "explicit" {
+		} else if token.Kind == ExplicitParameter {
 			// It's an explicit positional parameter.  Replace it with an
 			// implicit positional parameter, and append the appropriate
 			// binding from `positionals`.
@@ -52,39 +134,59 @@ func arrange(tokens []Token, bindings map[string]interface{}, positionals ...int
 				panic("Now just wait a damned minute!")
 			}
 
-			if i == 0 {
-				whine := fmt.Errorf(
-					"invalid explicit positional parameter %q.  Index is one-based",
-					token.Text)
-				return nil, nil, whine
+			if i == 0 || i > len(positionals) {
+				whine := &BadIndexError{
+					Parameter: token.Text,
+					Index:     i,
+					Count:     len(positionals),
+					Position:  Position{Offset: token.Offset}}
+				if fail(whine) {
+					return nil, nil, whine
+				}
+				continue
 			}
 
-			i--
-			if i >= len(positionals) {
-				whine := fmt.Errorf(
-					"explicit positional parameter %q does not have a corresponding positional binding",
-					token.Text)
-				return nil, nil, whine
+			if err := appendParameter(token, positionals[i-1]); err != nil && fail(err) {
+				return nil, nil, err
 			}
-			appendParameter(positionals[i])
-		} else if token.Kind == "implicit" {
+		} else if token.Kind == ImplicitParameter {
 			// It's an implicit positional parameter.  Make sure that we
 			// haven't run out of positional bindings, and then append the
 			// appropriate parameter.
-			if nextPositionalIndex == len(positionals) {
-				whine := fmt.Errorf(
-					"implicit positional parameter %q does not have a corresponding positional binding",
-					token.Text)
-				return nil, nil, whine
+			if nextPositionalIndex >= len(positionals) {
+				whine := &MissingPositionalError{
+					Parameter: token.Text,
+					Index:     nextPositionalIndex + 1,
+					Position:  Position{Offset: token.Offset}}
+				if fail(whine) {
+					return nil, nil, whine
+				}
+				nextPositionalIndex++
+				continue
+			}
+			if err := appendParameter(token, positionals[nextPositionalIndex]); err != nil && fail(err) {
+				return nil, nil, err
 			}
-			appendParameter(positionals[nextPositionalIndex])
 			nextPositionalIndex++
+		} else if token.Kind == Unterminated {
+			// It's an unterminated string or comment, so whatever follows
+			// can't be interpreted.
+			whine := &UnterminatedError{
+				Delimiter: token.Inside,
+				Position:  Position{Offset: token.Offset}}
+			if fail(whine) {
+				return nil, nil, whine
+			}
 		} else {
 			// non-parameter tokens just get forwarded to the output
 			outputTokens = append(outputTokens, token)
 		}
 	}
 
+	if len(problems) != 0 {
+		return nil, nil, errors.Join(problems...)
+	}
+
 	return outputTokens, outputBindings, nil
 }
 
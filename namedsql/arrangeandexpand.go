@@ -3,18 +3,7 @@ package namedsql
 // ArrangeAndExpand performs Arrange followed by Expand, but parses the query
 // only once.
 func ArrangeAndExpand(query string, bindings map[string]interface{}, positionals ...interface{}) (string, []interface{}, error) {
-	tokens := Lex(query)
-	tokens, positionals, err := arrange(tokens, bindings, positionals...)
-	if err != nil {
-		return "", nil, err
-	}
-
-	tokens, positionals, err = expand(tokens, positionals...)
-	if err != nil {
-		return "", nil, err
-	}
-
-	return Render(tokens), positionals, nil
+	return Options{}.ArrangeAndExpand(query, bindings, positionals...)
 }
 
 // MustArrangeAndExpand forwards to ArrangeAndExpand, except that its return
@@ -0,0 +1,151 @@
+package namedsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Batch is one of the batches of a script, as separated by "GO" lines in SQL
+// Server.
+type Batch struct {
+	// Query is the text of the batch.
+	Query string
+
+	// Bindings are the output bindings of Query.  Dialect.Batches leaves
+	// Bindings empty.
+	Bindings []interface{}
+
+	// Offset is the zero-based byte offset of the batch in the script.
+	Offset int
+}
+
+// Batches splits script into batches.  If dialect.GoBatches is true, then
+// each line containing only "GO" (in any case, and not within a string or
+// comment) separates two batches.  Otherwise, the entire script is one batch.
+// Batches that contain only whitespace are omitted.
+func (dialect Dialect) Batches(script string) []Batch {
+	batches := []Batch{}
+	for _, tokens := range dialect.batches(dialect.Lex(script)) {
+		batches = append(batches, Batch{Query: Render(tokens), Bindings: []interface{}{}, Offset: tokens[0].Offset})
+	}
+	return batches
+}
+
+// ArrangeAndExpandBatches splits script into batches as by Dialect.Batches,
+// and arranges and expands each batch as by ArrangeAndExpand.  Every batch
+// shares the same bindings.  If options.Strict is true, then it's an error for
+// a binding not to be referred to by any batch.  The positions of errors are
+// relative to script.
+func (options Options) ArrangeAndExpandBatches(script string, bindings map[string]interface{}) ([]Batch, error) {
+	lexed := options.Dialect.Lex(script)
+
+	// A binding need only be used by one of the batches, so check the whole
+	// script afterward, rather than each batch.
+	strict := options.Strict
+	options.Strict = false
+
+	batches := []Batch{}
+	for i, batchTokens := range options.Dialect.batches(lexed) {
+		tokens, outputBindings, err := options.arrangeTokens(batchTokens, bindings)
+		if err == nil {
+			tokens, outputBindings, err = expand(tokens, outputBindings...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("in batch %d: %w", i+1, locate(err, script))
+		}
+
+		query, outputBindings, err := options.finish(script, tokens, outputBindings)
+		if err != nil {
+			return nil, fmt.Errorf("in batch %d: %w", i+1, err)
+		}
+
+		batches = append(batches, Batch{Query: query, Bindings: outputBindings, Offset: batchTokens[0].Offset})
+	}
+
+	options.Strict = strict
+	if err := options.strict(lexed, bindings, nil); err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+// batches divides the lexed tokens of a script into the tokens of each of its
+// batches, as described for Dialect.Batches.
+func (dialect Dialect) batches(tokens []Token) [][]Token {
+	batches := [][]Token{}
+	var current []Token
+
+	// finishBatch appends the current batch to batches, unless it's blank.
+	finishBatch := func() {
+		for _, token := range current {
+			if token.Kind != Whitespace {
+				batches = append(batches, current)
+				break
+			}
+		}
+		current = nil
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		if !dialect.GoBatches || !isGoLine(tokens, i) {
+			current = append(current, tokens[i])
+			continue
+		}
+
+		// The batch keeps the line break before the "GO" line, but not the
+		// spaces or tabs on the "GO" line.
+		if last := len(current) - 1; last >= 0 && current[last].Kind == Whitespace {
+			before := current[last]
+			current = current[:last]
+			if lineEnd := strings.LastIndexByte(before.Text, '\n') + 1; lineEnd != 0 {
+				current = append(current, sliceToken(before, 0, lineEnd))
+			}
+		}
+		finishBatch()
+
+		// The next batch begins after the line break that ends the "GO" line.
+		if i+1 < len(tokens) {
+			i++
+			after := tokens[i]
+			if lineEnd := strings.IndexByte(after.Text, '\n') + 1; lineEnd != 0 && lineEnd < len(after.Text) {
+				current = append(current, sliceToken(after, lineEnd, len(after.Text)))
+			}
+		}
+	}
+
+	finishBatch()
+	return batches
+}
+
+// isGoLine returns whether tokens[i] is the word "GO" (in any case) alone on
+// its line, apart from whitespace.  For example, "GO -- comment" is not a
+// "GO" line.  If it is a "GO" line, then the token after it, if any, is
+// whitespace.
+func isGoLine(tokens []Token, i int) bool {
+	if tokens[i].Kind != Word || !strings.EqualFold(tokens[i].Text, "go") {
+		return false
+	}
+
+	// lineBegins returns whether tokens[j] begins a line.
+	lineBegins := func(j int) bool {
+		return j == 0 || strings.HasSuffix(tokens[j-1].Text, "\n")
+	}
+
+	if i > 0 && tokens[i-1].Kind == Whitespace {
+		if !strings.Contains(tokens[i-1].Text, "\n") && !lineBegins(i-1) {
+			return false
+		}
+	} else if !lineBegins(i) {
+		return false
+	}
+
+	if i+1 < len(tokens) {
+		after := tokens[i+1]
+		if after.Kind != Whitespace || !strings.Contains(after.Text, "\n") && i+2 < len(tokens) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,78 @@
+package namedsql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBatchesBreathing(t *testing.T) {
+	script := "create table t (x int)\nGO\n\ninsert into t values ('\nGO\n') -- GO\n  go  \n\ngo\n"
+	expected := []Batch{
+		{Query: "create table t (x int)\n", Bindings: []interface{}{}, Offset: 0},
+		{Query: "\ninsert into t values ('\nGO\n') -- GO\n", Bindings: []interface{}{}, Offset: 26}}
+
+	actual := SQLServer.Batches(script)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d batches but got %d: %q", len(expected), len(actual), actual)
+	}
+	for i := range expected {
+		if actual[i].Query != expected[i].Query || actual[i].Offset != expected[i].Offset {
+			t.Errorf("batch %d not as expected.\nexpected: %q\nactual: %q", i, expected[i], actual[i])
+		}
+	}
+
+	// Other dialects don't have batches.
+	if actual := Generic.Batches(script); len(actual) != 1 || actual[0].Query != script {
+		t.Errorf("expected the whole script as one batch, but got %q", actual)
+	}
+}
+
+func TestBatchesNotAlone(t *testing.T) {
+	for _, script := range []string{"select 1 GO", "select 1\nGO -- comment", "select '\nGO\n'", "select 1\ngo_on"} {
+		if actual := SQLServer.Batches(script); len(actual) != 1 || actual[0].Query != script {
+			t.Errorf("expected %q to be one batch, but got %q", script, actual)
+		}
+	}
+}
+
+func TestArrangeAndExpandBatches(t *testing.T) {
+	script := "declare @n int = @start\nselect @n, x from t where x in @xs\ngo\nselect [weird @name] from u where y = @y\n"
+	bindings := map[string]interface{}{"start": 1, "xs": []int{2, 3}, "y": 4}
+
+	batches, err := Options{Dialect: SQLServer}.ArrangeAndExpandBatches(script, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Batch{
+		{Query: "declare @n int = @p1\nselect @n, x from t where x in (@p2, @p3)\n", Bindings: []interface{}{1, 2, 3}},
+		{Query: "select [weird @name] from u where y = @p1\n", Bindings: []interface{}{4}, Offset: 62}}
+	if len(batches) != len(expected) {
+		t.Fatalf("expected %d batches but got %d: %q", len(expected), len(batches), batches)
+	}
+	for i, batch := range batches {
+		if batch.Query != expected[i].Query || batch.Offset != expected[i].Offset {
+			t.Errorf("batch %d not as expected.\nexpected: %q\nactual: %q", i, expected[i], batch)
+		}
+		message := sliceDisagreement(sliceCheck{actual: batch.Bindings, expected: expected[i].Bindings})
+		if message != "" {
+			t.Error(message)
+		}
+	}
+
+	// Errors are located within the script, not within the batch.
+	_, err = Options{Dialect: SQLServer}.ArrangeAndExpandBatches(script, map[string]interface{}{"start": 1, "xs": 2})
+	if err == nil || !strings.Contains(err.Error(), "in batch 2") || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("expected an error about @y in batch 2 on line 4, but got: %v", err)
+	}
+
+	// A binding need only be used by one batch.
+	options := Options{Dialect: SQLServer, Strict: true}
+	if _, err := options.ArrangeAndExpandBatches(script, bindings); err != nil {
+		t.Error(err)
+	}
+	bindings["unused"] = 5
+	if _, err := options.ArrangeAndExpandBatches(script, bindings); err == nil {
+		t.Error("expected an error for the unused binding in strict mode")
+	}
+}
@@ -0,0 +1,195 @@
+package namedsql
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Secret wraps a binding whose value must not appear in logs.  Debug renders
+// a Secret, or a pointer to one, as "[redacted]".  Secret implements
+// driver.Valuer, so it can also be used as an ordinary binding.  Since a
+// Secret is a single value, Expand does not expand a Secret that wraps a
+// slice.
+type Secret struct {
+	Binding interface{}
+}
+
+// Value returns the wrapped binding, converted as database/sql would convert
+// it.
+func (secret Secret) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(secret.Binding)
+}
+
+// String returns "[redacted]".
+func (secret Secret) String() string {
+	return redacted
+}
+
+// GoString returns "[redacted]".
+func (secret Secret) GoString() string {
+	return redacted
+}
+
+// LogValue returns "[redacted]".
+func (secret Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// redacted is how Debug renders sensitive bindings.
+const redacted = "[redacted]"
+
+// SensitiveNames matches the names of named parameters that Debug redacts.
+// Each word must be the whole name or be separated from the rest of it by
+// underscores, e.g. "db_password" but not "passenger_id".
+var SensitiveNames = regexp.MustCompile(
+	`(?i)(?:^|_)(?:pass(?:word|wd|phrase)?|secret|token|api_?key|credentials?)(?:_|$)`)
+
+// defaultListLimit is the number of elements of a list binding that a
+// Debugger shows when its ListLimit is zero.
+const defaultListLimit = 3
+
+// Debugger renders queries with their bindings inlined, for people to read.
+// The zero value of Debugger redacts only Secret bindings.
+type Debugger struct {
+	// Dialect determines how Identifier bindings are quoted.
+	Dialect Dialect
+
+	// Redact, if not nil, matches the names of named parameters whose
+	// bindings are rendered as "[redacted]".
+	Redact *regexp.Regexp
+
+	// ListLimit is the number of elements of a list binding that are shown
+	// before the rest are summarized, e.g. "(1, 2, 3, … 997 more)".  If
+	// ListLimit is zero, three elements are shown.
+	ListLimit int
+}
+
+// DebugQuery is a query with its bindings inlined, as returned by Debug.
+// Bindings are formatted for people rather than escaped for a database, and
+// lists may be truncated, so a DebugQuery must never be executed.  That's why
+// it's not a string.  Use its String method for text, or log it directly
+// with log/slog.
+type DebugQuery struct {
+	text string
+	err  error
+}
+
+// String returns the query with its bindings inlined.  If the query could
+// not be arranged, String returns the original query followed by the error.
+func (query DebugQuery) String() string {
+	if query.err != nil {
+		return fmt.Sprintf("%s [error: %v]", query.text, query.err)
+	}
+	return query.text
+}
+
+// Err returns the error that prevented the query from being arranged, or nil
+// if there was none.
+func (query DebugQuery) Err() error {
+	return query.err
+}
+
+// LogValue returns a group containing the query with its bindings inlined
+// and, if the query could not be arranged, the error.
+func (query DebugQuery) LogValue() slog.Value {
+	if query.err != nil {
+		return slog.GroupValue(
+			slog.String("query", query.text),
+			slog.String("error", query.err.Error()))
+	}
+	return slog.GroupValue(slog.String("query", query.text))
+}
+
+// Debug returns query with its bindings inlined, for people to read.  Its
+// parameters are the same as those of Arrange.  Bindings of named parameters
+// whose names match SensitiveNames, as well as Secret bindings, are redacted.
+// Lists of more than three elements are truncated.
+func Debug(query string, bindings map[string]interface{}, positionals ...interface{}) DebugQuery {
+	return Debugger{Redact: SensitiveNames}.Debug(query, bindings, positionals...)
+}
+
+// Debug is like the package-level Debug, but configured by debugger.
+func (debugger Debugger) Debug(query string, bindings map[string]interface{}, positionals ...interface{}) DebugQuery {
+	// arrange wraps the bindings of sensitive names as secrets, including
+	// those within fragments.
+	options := Options{Dialect: debugger.Dialect, redact: debugger.Redact}
+	tokens, err := options.optional(debugger.Dialect.Lex(query), bindings, positionals...)
+	if err != nil {
+		return DebugQuery{text: query, err: locate(err, query)}
+	}
+
+	tokens, outputBindings, err := options.arrange(tokens, bindings, positionals...)
+	if err != nil {
+		return DebugQuery{text: query, err: locate(err, query)}
+	}
+
+	// arrange outputs one binding per implicit positional parameter.
+	texts := make([]string, len(tokens))
+	bindingIndex := 0
+	for i, token := range tokens {
+		switch token.Kind {
+		case ImplicitParameter:
+			texts[i] = debugger.format(outputBindings[bindingIndex])
+			bindingIndex++
+		default:
+			texts[i] = debugger.Dialect.outputText(token, false, false)
+		}
+	}
+
+	return DebugQuery{text: strings.Join(texts, "")}
+}
+
+// format returns binding formatted for people to read.
+func (debugger Debugger) format(binding interface{}) string {
+	switch binding.(type) {
+	case Secret, *Secret:
+		return redacted
+	}
+
+	if elements, isSequence := unpackSequence(binding); isSequence {
+		limit := debugger.ListLimit
+		if limit == 0 {
+			limit = defaultListLimit
+		}
+
+		texts := make([]string, 0, limit+1)
+		for i, element := range elements {
+			if i == limit {
+				texts = append(texts, fmt.Sprintf("… %d more", len(elements)-limit))
+				break
+			}
+			texts = append(texts, debugger.format(element))
+		}
+		return "(" + strings.Join(texts, ", ") + ")"
+	}
+
+	if valuer, isValuer := binding.(driver.Valuer); isValuer {
+		value, err := valuer.Value()
+		if err != nil {
+			return fmt.Sprintf("[error: %v]", err)
+		}
+		binding = value
+	}
+
+	switch binding := binding.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return strconv.Quote(binding)
+	case []byte:
+		if len(binding) > 16 {
+			return fmt.Sprintf("X'%X…' (%d bytes)", binding[:16], len(binding))
+		}
+		return "X'" + strings.ToUpper(hex.EncodeToString(binding)) + "'"
+	case time.Time:
+		return "'" + binding.Format(timeLayout) + "'"
+	default:
+		return fmt.Sprint(binding)
+	}
+}
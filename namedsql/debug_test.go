@@ -0,0 +1,102 @@
+package namedsql
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDebugBreathing(t *testing.T) {
+	ids := make([]int, 1000)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	actual := Debug(
+		"select * from users where id in @ids and name = :name and password = @password and ssn = ? and x = ?",
+		map[string]interface{}{"ids": ids, "name": "O'Brien", "password": "hunter2"},
+		Secret{"123-45-6789"},
+		nil).String()
+
+	expected := `select * from users where id in (1, 2, 3, … 997 more) and name = "O'Brien" ` +
+		`and password = [redacted] and ssn = [redacted] and x = NULL`
+	if actual != expected {
+		t.Errorf("debug output not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+}
+
+func TestDebugFragments(t *testing.T) {
+	filter := NewFragment("pw = @password and n = @n", map[string]interface{}{"password": "hunter2", "n": 1})
+	actual := Debug("select * from t where @filter", map[string]interface{}{"filter": filter}).String()
+
+	expected := "select * from t where pw = [redacted] and n = 1"
+	if actual != expected {
+		t.Errorf("debug output not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+}
+
+func TestDebugSecretPointer(t *testing.T) {
+	secret := &Secret{"s3cret"}
+	actual := Debug("select @x, ?", map[string]interface{}{"x": secret}, []interface{}{secret}).String()
+
+	expected := "select [redacted], ([redacted])"
+	if actual != expected {
+		t.Errorf("debug output not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+}
+
+func TestDebugSensitiveNames(t *testing.T) {
+	for _, name := range []string{"password", "DB_PASSWORD", "pass", "api_key", "apikey",
+		"client_secret", "access_token", "token_hash", "credentials"} {
+		if !SensitiveNames.MatchString(name) {
+			t.Errorf("expected %q to be sensitive", name)
+		}
+	}
+
+	for _, name := range []string{"passenger_id", "compass", "bypass_cache", "tokens_used",
+		"secretary", "tokenizer"} {
+		if SensitiveNames.MatchString(name) {
+			t.Errorf("expected %q not to be sensitive", name)
+		}
+	}
+}
+
+func TestDebugConfigured(t *testing.T) {
+	debugger := Debugger{Redact: regexp.MustCompile("^email$"), ListLimit: 1}
+	actual := debugger.Debug(
+		"select * from t where password = @password and email = @email and x in @xs",
+		map[string]interface{}{"password": "hunter2", "email": "a@b.c", "xs": []int{1, 2}}).String()
+
+	expected := `select * from t where password = "hunter2" and email = [redacted] and x in (1, … 1 more)`
+	if actual != expected {
+		t.Errorf("debug output not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+}
+
+func TestDebugLogValue(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		}}))
+
+	logger.Info("failed", "sql", Debug("select @token, @missing", map[string]interface{}{"token": "abc"}))
+	logger.Info("ran", "sql", Debug("select @token", map[string]interface{}{"token": "abc"}))
+
+	output := buffer.String()
+	if strings.Contains(output, "abc") {
+		t.Errorf("secret leaked into log output: %s", output)
+	}
+	for _, expected := range []string{
+		`sql.query="select @token, @missing" sql.error=`,
+		`sql.query="select [redacted]"`} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected log output to contain %q, but got: %s", expected, output)
+		}
+	}
+}
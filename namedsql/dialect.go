@@ -0,0 +1,276 @@
+package namedsql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Placeholder is a style of positional parameter written into the queries
+// output by Arrange, Expand, and ArrangeAndExpand.
+type Placeholder int
+
+const (
+	// Question is the "?" style used by MySQL, SQLite, and ODBC.  It's the
+	// zero value, and so the default.
+	Question Placeholder = iota
+
+	// Dollar is the "$1, $2, ..." style used by Postgres.
+	Dollar
+
+	// Colon is the ":1, :2, ..." style used by Oracle.
+	Colon
+
+	// AtP is the "@p1, @p2, ..." style used by SQL Server.
+	AtP
+
+	// Named is the ":name" style supported by Oracle.  Named parameters keep
+	// their names, and the output bindings are sql.NamedArg values.
+	Named
+
+	// QuestionNumber is the "?1, ?2, ..." style supported by SQLite.  A named
+	// or explicit positional parameter that occurs more than once has only
+	// one output binding.
+	QuestionNumber
+
+	// Brace is the "{p1:Type}, {p2:Type}, ..." style used by ClickHouse,
+	// where each Type is taken from the corresponding typed parameter, e.g.
+	// "{id:UInt64}".  The output bindings are sql.NamedArg values; see
+	// NamedValues.
+	Brace
+
+	// Percent is the "%s" style used by Python's DB-API.  Literal percent
+	// signs in output queries are escaped as "%%".
+	Percent
+)
+
+// placeholderNames maps each Placeholder to the name used for it in String
+// and ParsePlaceholder.
+var placeholderNames = map[Placeholder]string{
+	Question:       "question",
+	Dollar:         "dollar",
+	Colon:          "colon",
+	AtP:            "atp",
+	Named:          "named",
+	QuestionNumber: "question-number",
+	Brace:          "brace",
+	Percent:        "percent"}
+
+// String returns the name of the placeholder style, e.g. "dollar".
+func (placeholder Placeholder) String() string {
+	if name, ok := placeholderNames[placeholder]; ok {
+		return name
+	}
+	return "Placeholder(" + strconv.Itoa(int(placeholder)) + ")"
+}
+
+// ParsePlaceholder returns the Placeholder having the specified name, as
+// returned by Placeholder.String.  It returns an error if there is no such
+// Placeholder.
+func ParsePlaceholder(name string) (Placeholder, error) {
+	for placeholder, placeholderName := range placeholderNames {
+		if placeholderName == name {
+			return placeholder, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown placeholder style %q", name)
+}
+
+// format returns the text of the position'th (one-based) parameter in this
+// placeholder style, e.g. "$3".
+func (placeholder Placeholder) format(position int) string {
+	switch placeholder {
+	case Dollar:
+		return "$" + strconv.Itoa(position)
+	case Colon:
+		return ":" + strconv.Itoa(position)
+	case AtP:
+		return "@p" + strconv.Itoa(position)
+	case QuestionNumber:
+		return "?" + strconv.Itoa(position)
+	case Percent:
+		return "%s"
+	default:
+		return "?"
+	}
+}
+
+// Dialect describes the conventions of a particular database's SQL that are
+// relevant to rewriting queries.  The zero value of Dialect behaves like
+// Generic.
+type Dialect struct {
+	// Name identifies the dialect, e.g. "postgres".
+	Name string
+
+	// Placeholder is the style of positional parameter written into output
+	// queries.
+	Placeholder Placeholder
+
+	// IdentifierQuote is the style of quoting used for Identifier bindings.
+	IdentifierQuote IdentifierQuote
+
+	// StandardStrings is whether string literals follow standard SQL, where
+	// backslash is an ordinary character and quotes are escaped by doubling
+	// them, rather than MySQL, where backslash is an escape character.  This
+	// affects both how queries are lexed and how strings are interpolated.
+	StandardStrings bool
+
+	// BytesLiteral is the style of literal used for []byte bindings when
+	// they're interpolated.
+	BytesLiteral BytesLiteral
+
+	// BoolLiteral is the style of literal used for bool bindings when
+	// they're interpolated.
+	BoolLiteral BoolLiteral
+
+	// TimeLiteral is the style of literal used for time.Time bindings when
+	// they're interpolated.
+	TimeLiteral TimeLiteral
+
+	// NationalStrings is whether string bindings are interpolated as
+	// national (Unicode) strings, e.g. N'text', as in SQL Server, so that
+	// characters outside of the database's code page are preserved.
+	NationalStrings bool
+
+	// HashComments is whether "#" begins a comment that continues until the
+	// end of the line, as in MySQL.
+	HashComments bool
+
+	// UserVariables is whether "@name" might be a MySQL user variable rather
+	// than a parameter.  If UserVariables is true, then "@name" is a parameter
+	// only if there is a binding for "name", and "@@name" is always a system
+	// variable.
+	UserVariables bool
+
+	// LocalVariables is whether variables declared within a query, as in
+	// "DECLARE @total int", are left alone rather than treated as parameters,
+	// as in SQL Server.  If LocalVariables is true, then "@@name" is always a
+	// system function.
+	LocalVariables bool
+
+	// GoBatches is whether a line containing only "GO" separates the batches
+	// of a script, as in SQL Server.  See Batches.
+	GoBatches bool
+
+	// QQuotes is whether strings may be quoted alternatively, as in Oracle's
+	// q'[it's]' and q'!it's!'.
+	QQuotes bool
+
+	// BracketIdentifiers is whether "[name]" is a quoted identifier, as in
+	// SQL Server and SQLite.
+	BracketIdentifiers bool
+
+	// QuestionOperators is whether "?|" and "?&" are operators, as in
+	// Postgres's JSONB, rather than parameters followed by "|" or "&".  The
+	// JSONB operator "?" must be escaped as "??" regardless.
+	QuestionOperators bool
+
+	// DoubleQuoteStrings is whether double quotes enclose strings, as in
+	// MySQL, rather than identifiers.  It affects only the Kind of the lexed
+	// Token.
+	DoubleQuoteStrings bool
+
+	// DollarQuotes is whether strings can be dollar-quoted, as in Postgres,
+	// e.g. "$$it's a string$$" or "$body$ ... $body$".
+	DollarQuotes bool
+
+	// Lexer selects the syntaxes recognized as parameters in input queries,
+	// e.g. "?3" and "$name" in SQLite.
+	Lexer Lexer
+
+	// PercentEscapes is whether queries are written for Python's DB-API, in
+	// which a literal percent sign is escaped as "%%" even within strings,
+	// e.g. "like 'abc%%'".  Outside of strings and comments, "%%" is an
+	// escaped "%" regardless.
+	PercentEscapes bool
+}
+
+var (
+	// Generic is the dialect used by the package-level functions.  It
+	// outputs "?" parameters.
+	Generic = Dialect{Name: "generic"}
+
+	// MySQL outputs "?" parameters, quotes identifiers with backticks, and
+	// understands "#" comments and user variables.
+	MySQL = Dialect{
+		Name:               "mysql",
+		IdentifierQuote:    Backtick,
+		HashComments:       true,
+		UserVariables:      true,
+		DoubleQuoteStrings: true}
+
+	// Postgres outputs "$1, $2, ..." parameters, has standard strings and
+	// dollar-quoted strings, and understands JSONB's "?|" and "?&" operators.
+	Postgres = Dialect{
+		Name:              "postgres",
+		Placeholder:       Dollar,
+		StandardStrings:   true,
+		DollarQuotes:      true,
+		BytesLiteral:      Bytea,
+		QuestionOperators: true}
+
+	// SQLServer outputs "@p1, @p2, ..." parameters, quotes identifiers with
+	// brackets, leaves declared variables alone, and separates batches with
+	// "GO".
+	SQLServer = Dialect{
+		Name:               "sqlserver",
+		Placeholder:        AtP,
+		IdentifierQuote:    Brackets,
+		StandardStrings:    true,
+		BytesLiteral:       HexNumber,
+		BoolLiteral:        OneZero,
+		TimeLiteral:        LocalString,
+		NationalStrings:    true,
+		LocalVariables:     true,
+		GoBatches:          true,
+		BracketIdentifiers: true}
+
+	// Oracle outputs ":name" parameters and sql.NamedArg bindings, and
+	// understands alternatively quoted strings.
+	Oracle = Dialect{
+		Name:            "oracle",
+		Placeholder:     Named,
+		StandardStrings: true,
+		BytesLiteral:    HexToRaw,
+		BoolLiteral:     OneZero,
+		TimeLiteral:     Timestamp,
+		QQuotes:         true}
+
+	// SQLite outputs "?" parameters, has standard strings, and understands
+	// "?3", "$name", and bracketed identifiers.
+	SQLite = Dialect{
+		Name:               "sqlite",
+		StandardStrings:    true,
+		BracketIdentifiers: true,
+		Lexer:              Lexer{Parameters: DefaultParameters | QuestionNumberParameters | DollarNameParameters}}
+
+	// ClickHouse outputs "{p1:Type}, {p2:Type}, ..." parameters and quotes
+	// identifiers with backticks.
+	ClickHouse = Dialect{
+		Name:            "clickhouse",
+		Placeholder:     Brace,
+		IdentifierQuote: Backtick}
+)
+
+// dialects are the predefined dialects, in the order they're listed by
+// Dialects.
+var dialects = []*Dialect{&Generic, &MySQL, &Postgres, &SQLServer, &Oracle, &SQLite, &ClickHouse}
+
+// Dialects returns the names of the predefined dialects.
+func Dialects() []string {
+	names := make([]string, len(dialects))
+	for i, dialect := range dialects {
+		names[i] = dialect.Name
+	}
+	return names
+}
+
+// LookupDialect returns the predefined Dialect having the specified name.  It
+// returns an error if there is no such Dialect.
+func LookupDialect(name string) (Dialect, error) {
+	for _, dialect := range dialects {
+		if dialect.Name == name {
+			return *dialect, nil
+		}
+	}
+	return Dialect{}, fmt.Errorf("unknown dialect %q", name)
+}
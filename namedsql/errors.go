@@ -0,0 +1,274 @@
+package namedsql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is the location of a parameter within a query.
+type Position struct {
+	// Offset is the zero-based byte offset of the parameter in the query.
+	Offset int
+
+	// Line is the one-based line number of the parameter in the query.
+	Line int
+
+	// Column is the one-based column number, counted in characters, of the
+	// parameter within its line.
+	Column int
+
+	// text is the entire line containing the parameter, for excerpts.
+	text string
+}
+
+// locate fills in the line and column of the position from its offset into
+// query, unless they've been filled in already.  The line and column might
+// have been filled in already if the position is within a Fragment, in which
+// case they're relative to the Fragment's query.
+func (position *Position) locate(query string) {
+	if position.Line != 0 || position.Offset > len(query) {
+		return
+	}
+
+	lineBegin := strings.LastIndexByte(query[:position.Offset], '\n') + 1
+	lineEnd := strings.IndexByte(query[position.Offset:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(query)
+	} else {
+		lineEnd += position.Offset
+	}
+
+	position.Line = strings.Count(query[:lineBegin], "\n") + 1
+	position.Column = utf8.RuneCountInString(query[lineBegin:position.Offset]) + 1
+	position.text = query[lineBegin:lineEnd]
+}
+
+// excerpt returns a description of the position followed by the line
+// containing it, underlined with carets from the position for the length of
+// parameter.  For example,
+//
+//	at line 2, column 14:
+//	    where color = @color
+//	                  ^^^^^^
+func (position Position) excerpt(parameter string) string {
+	if position.Line == 0 {
+		return fmt.Sprintf("at offset %d", position.Offset)
+	}
+
+	// Tabs are kept in the padding before the carets so that the carets line
+	// up with the text above them.
+	var padding strings.Builder
+	column := 1
+	for _, char := range position.text {
+		if column == position.Column {
+			break
+		}
+		if char == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+		column++
+	}
+
+	carets := utf8.RuneCountInString(parameter)
+	if remaining := utf8.RuneCountInString(position.text) - (position.Column - 1); carets > remaining {
+		carets = remaining
+	}
+	if carets < 1 {
+		carets = 1
+	}
+
+	return fmt.Sprintf("at line %d, column %d:\n    %s\n    %s%s",
+		position.Line, position.Column, position.text, padding.String(), strings.Repeat("^", carets))
+}
+
+// locatable is implemented by errors having a Position.
+type locatable interface {
+	locate(query string)
+}
+
+// locate fills in the line and column of the positions of err and of the
+// errors wrapped by err, from their offsets into query.  It returns err.
+func locate(err error, query string) error {
+	switch err := err.(type) {
+	case locatable:
+		err.locate(query)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range err.Unwrap() {
+			locate(wrapped, query)
+		}
+	case interface{ Unwrap() error }:
+		locate(err.Unwrap(), query)
+	}
+	return err
+}
+
+// MissingBindingError is returned when a named parameter has no binding.
+type MissingBindingError struct {
+	// Parameter is the text of the parameter, e.g. "@color".
+	Parameter string
+
+	// Name is the name of the parameter, e.g. "color".
+	Name string
+
+	Position
+}
+
+func (err *MissingBindingError) Error() string {
+	return fmt.Sprintf("named parameter %q does not have a corresponding binding %s",
+		err.Parameter, err.excerpt(err.Parameter))
+}
+
+// BadIndexError is returned when an explicit positional parameter has an
+// index of zero, or an index greater than the number of positional bindings.
+type BadIndexError struct {
+	// Parameter is the text of the parameter, e.g. ":3".
+	Parameter string
+
+	// Index is the one-based index of the parameter, e.g. 3.
+	Index int
+
+	// Count is the number of positional bindings.
+	Count int
+
+	Position
+}
+
+func (err *BadIndexError) Error() string {
+	if err.Index == 0 {
+		return fmt.Sprintf("invalid explicit positional parameter %q.  Index is one-based %s",
+			err.Parameter, err.excerpt(err.Parameter))
+	}
+	return fmt.Sprintf("explicit positional parameter %q does not have a corresponding "+
+		"positional binding.  There are %d positional bindings %s",
+		err.Parameter, err.Count, err.excerpt(err.Parameter))
+}
+
+// MissingPositionalError is returned when an implicit positional parameter
+// has no binding, because there are too few positional bindings.
+type MissingPositionalError struct {
+	// Parameter is the text of the parameter, e.g. "?".
+	Parameter string
+
+	// Index is the one-based index of the parameter among the implicit
+	// positional parameters.
+	Index int
+
+	Position
+}
+
+func (err *MissingPositionalError) Error() string {
+	return fmt.Sprintf("implicit positional parameter %q does not have a corresponding "+
+		"positional binding.  It is implicit positional parameter number %d %s",
+		err.Parameter, err.Index, err.excerpt(err.Parameter))
+}
+
+// ExplicitInExpandError is returned when a query passed to Expand contains
+// an explicit positional parameter.
+type ExplicitInExpandError struct {
+	// Parameter is the text of the parameter, e.g. ":3".
+	Parameter string
+
+	Position
+}
+
+func (err *ExplicitInExpandError) Error() string {
+	return fmt.Sprintf("explicit positional parameters are not allowed in Expand.  parameter %q %s",
+		err.Parameter, err.excerpt(err.Parameter))
+}
+
+// ImplicitInOptionalError is returned when an optional fragment contains an
+// implicit positional parameter, since dropping the fragment would change
+// which binding each later implicit positional parameter refers to.
+type ImplicitInOptionalError struct {
+	// Parameter is the text of the parameter, e.g. "?".
+	Parameter string
+
+	Position
+}
+
+func (err *ImplicitInOptionalError) Error() string {
+	return fmt.Sprintf("implicit positional parameter %q is not allowed in an optional fragment %s",
+		err.Parameter, err.excerpt(err.Parameter))
+}
+
+// UnclosedOptionalError is returned when an optional fragment begun by "[["
+// is not closed by a matching "]]".
+type UnclosedOptionalError struct {
+	// Delimiter is the opening delimiter, i.e. "[[".
+	Delimiter string
+
+	Position
+}
+
+func (err *UnclosedOptionalError) Error() string {
+	return fmt.Sprintf("optional fragment opened by %q is not closed by \"]]\" %s",
+		err.Delimiter, err.excerpt(err.Delimiter))
+}
+
+// UnterminatedError is returned when a query contains a string, quoted
+// identifier, or block comment that is missing its closing delimiter.
+// Parameters after the opening delimiter are not bound.
+type UnterminatedError struct {
+	// Delimiter is the opening delimiter, e.g. "'" or "/*".
+	Delimiter string
+
+	Position
+}
+
+func (err *UnterminatedError) Error() string {
+	return fmt.Sprintf("%q is never closed %s", err.Delimiter, err.excerpt(err.Delimiter))
+}
+
+// MissingTypeError is returned when a parameter has no type, as in
+// "{id:UInt64}", but the placeholder style requires one.
+type MissingTypeError struct {
+	// Parameter is the text of the parameter, e.g. "@id".
+	Parameter string
+
+	Position
+}
+
+func (err *MissingTypeError) Error() string {
+	return fmt.Sprintf("parameter %q does not have a type, e.g. {name:String}, which the output "+
+		"placeholder style requires %s", err.Parameter, err.excerpt(err.Parameter))
+}
+
+// RecursiveFragmentError is returned when a Fragment is bound within itself,
+// directly or through other fragments.
+type RecursiveFragmentError struct {
+	// Parameter is the text of the parameter bound to the Fragment, e.g.
+	// "@filter".
+	Parameter string
+
+	Position
+}
+
+func (err *RecursiveFragmentError) Error() string {
+	return fmt.Sprintf("fragment bound to parameter %q is bound within itself %s",
+		err.Parameter, err.excerpt(err.Parameter))
+}
+
+// UnusedPositionalError is returned by Validate, and in strict mode, when a
+// positional binding is not referred to by any parameter.
+type UnusedPositionalError struct {
+	// Index is the one-based index of the positional binding.
+	Index int
+}
+
+func (err *UnusedPositionalError) Error() string {
+	return fmt.Sprintf("positional binding number %d is not referred to by any parameter", err.Index)
+}
+
+// UnusedBindingError is returned in strict mode when a named binding is not
+// referred to by any parameter.
+type UnusedBindingError struct {
+	// Name is the name of the binding.
+	Name string
+}
+
+func (err *UnusedBindingError) Error() string {
+	return fmt.Sprintf("binding %q is not referred to by any parameter", err.Name)
+}
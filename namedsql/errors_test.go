@@ -0,0 +1,106 @@
+package namedsql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorsMissingBinding(t *testing.T) {
+	query := "select *\nfrom cars\nwhere\tcolor = @color"
+	_, _, err := Arrange(query, map[string]interface{}{})
+
+	var missing *MissingBindingError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected a MissingBindingError, but got %v", err)
+	}
+
+	if missing.Name != "color" || missing.Offset != 33 || missing.Line != 3 || missing.Column != 15 {
+		t.Errorf("error not as expected: %#v", missing)
+	}
+
+	expected := "named parameter \"@color\" does not have a corresponding binding at line 3, column 15:\n" +
+		"    where\tcolor = @color\n" +
+		"         \t        ^^^^^^"
+	if err.Error() != expected {
+		t.Errorf("error message not as expected.\nexpected: %q\nactual: %q", expected, err.Error())
+	}
+}
+
+func TestErrorsPositional(t *testing.T) {
+	_, _, err := Arrange("select :0", nil, 1)
+	var badIndex *BadIndexError
+	if !errors.As(err, &badIndex) || badIndex.Index != 0 || badIndex.Column != 8 {
+		t.Errorf("expected a BadIndexError for index 0, but got %v", err)
+	}
+
+	_, _, err = Arrange("select ?, $3", nil, 1, 2)
+	if !errors.As(err, &badIndex) || badIndex.Index != 3 || badIndex.Count != 2 {
+		t.Errorf("expected a BadIndexError for index 3, but got %v", err)
+	}
+
+	_, _, err = Arrange("select ?, ?", nil, 1)
+	var missing *MissingPositionalError
+	if !errors.As(err, &missing) || missing.Index != 2 || missing.Column != 11 {
+		t.Errorf("expected a MissingPositionalError, but got %v", err)
+	}
+
+	_, _, err = Expand("select ?, :1", 1)
+	var explicit *ExplicitInExpandError
+	if !errors.As(err, &explicit) || explicit.Parameter != ":1" || explicit.Offset != 10 {
+		t.Errorf("expected an ExplicitInExpandError, but got %v", err)
+	}
+}
+
+func TestErrorsInFragment(t *testing.T) {
+	// The position of an error within a fragment is relative to the
+	// fragment's query.
+	fragment := NewFragment("x = 1\n  and y = @y", nil)
+	_, _, err := Arrange("select * from t\nwhere @filter", map[string]interface{}{"filter": fragment})
+
+	var missing *MissingBindingError
+	if !errors.As(err, &missing) || missing.Line != 2 || missing.Column != 11 {
+		t.Errorf("expected a MissingBindingError within the fragment, but got %v", err)
+	}
+}
+
+func TestErrorsOptional(t *testing.T) {
+	_, _, err := Arrange("select 1\n[[ and x = ? ]]", nil, 1)
+	var implicit *ImplicitInOptionalError
+	if !errors.As(err, &implicit) || implicit.Parameter != "?" || implicit.Line != 2 || implicit.Column != 12 {
+		t.Errorf("expected an ImplicitInOptionalError, but got %v", err)
+	}
+
+	query := "select 1 [[ and x = @x [[ and y = @y ]]"
+	_, _, err = Arrange(query, map[string]interface{}{"x": 1, "y": 2})
+	var unclosed *UnclosedOptionalError
+	if !errors.As(err, &unclosed) {
+		t.Fatalf("expected an UnclosedOptionalError, but got %v", err)
+	}
+
+	expected := "optional fragment opened by \"[[\" is not closed by \"]]\" at line 1, column 10:\n" +
+		"    select 1 [[ and x = @x [[ and y = @y ]]\n" +
+		"             ^^"
+	if err.Error() != expected {
+		t.Errorf("error message not as expected.\nexpected: %q\nactual: %q", expected, err.Error())
+	}
+}
+
+func TestErrorsUnterminated(t *testing.T) {
+	query := "select * from t\nwhere x = ? and y = 'it is ?"
+	_, _, arrangeErr := Arrange(query, nil, 1, 2)
+	_, _, expandErr := Expand(query, 1, 2)
+	_, _, optionalErr := Arrange("select 1 [[ and x = /* @x", map[string]interface{}{"x": 1})
+
+	for _, err := range []error{arrangeErr, expandErr, optionalErr} {
+		var unterminated *UnterminatedError
+		if !errors.As(err, &unterminated) {
+			t.Errorf("expected an UnterminatedError, but got %v", err)
+		}
+	}
+
+	var unterminated *UnterminatedError
+	errors.As(arrangeErr, &unterminated)
+	if unterminated.Delimiter != "'" || unterminated.Line != 2 || unterminated.Column != 21 {
+		t.Errorf("error not as expected: %v", arrangeErr)
+	}
+}
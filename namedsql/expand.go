@@ -1,8 +1,10 @@
 package namedsql
 
 import (
-	"fmt"
+	"database/sql/driver"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // Expand transforms the specified SQL query and bindings in the following way:
@@ -25,8 +27,7 @@ import (
 //     []interface{}{a, b, c, "foo"}
 //
 func Expand(query string, bindings ...interface{}) (string, []interface{}, error) {
-	tokens, bindings, err := expand(Lex(query), bindings...)
-	return Render(tokens), bindings, err
+	return Options{}.Expand(query, bindings...)
 }
 
 func expand(tokens []Token, bindings ...interface{}) ([]Token, []interface{}, error) {
@@ -34,30 +35,51 @@ func expand(tokens []Token, bindings ...interface{}) ([]Token, []interface{}, er
 	outputTokens := make([]Token, 0, len(tokens))
 	outputBindings := make([]interface{}, 0, len(bindings))
 	for _, token := range tokens {
-		if token.Kind == "implicit" {
+		if token.Kind == ImplicitParameter {
 			// It's a parameter. If the value is a sequence (e.g. a slice),
 			// replace the parameter "?" with a list of parameters
 			// "(?, ?, ...)" that refer to the sequence's elements.
 			if bindingIndex == len(bindings) {
-				whine := fmt.Errorf(
-					"implicit positional parameter %q does not have a corresponding positional binding",
-					token.Text)
+				whine := &MissingPositionalError{
+					Parameter: token.Text,
+					Index:     bindingIndex + 1,
+					Position:  Position{Offset: token.Offset}}
 				return nil, nil, whine
 			}
 			binding := bindings[bindingIndex]
 			elements, isSequence := unpackSequence(binding)
-			if isSequence {
-				outputTokens = append(outputTokens, parameterList(len(elements))...)
+			if isSequence && !isArrayType(token.Type) {
+				list := parameterList(len(elements), token)
+				name := parameterName(token)
+				position := 0
+				for i := range list {
+					if list[i].Kind != ImplicitParameter {
+						continue
+					}
+					// Name the elements after the list, e.g. "ids_1", and
+					// give them the list's type, if any.
+					position++
+					if name != "" {
+						list[i].Inside = name + "_" + strconv.Itoa(position)
+					}
+					list[i].Type = token.Type
+				}
+				outputTokens = append(outputTokens, list...)
 				outputBindings = append(outputBindings, elements...)
 			} else {
 				outputTokens = append(outputTokens, token)
 				outputBindings = append(outputBindings, binding)
 			}
 			bindingIndex++
-		} else if token.Kind == "explicit" {
-			whine := fmt.Errorf(
-				"explicit positional parameters are not allowed in Expand.  parameter: %q",
-				token.Text)
+		} else if token.Kind == ExplicitParameter {
+			whine := &ExplicitInExpandError{
+				Parameter: token.Text,
+				Position:  Position{Offset: token.Offset}}
+			return nil, nil, whine
+		} else if token.Kind == Unterminated {
+			whine := &UnterminatedError{
+				Delimiter: token.Inside,
+				Position:  Position{Offset: token.Offset}}
 			return nil, nil, whine
 		} else {
 			outputTokens = append(outputTokens, token)
@@ -67,6 +89,13 @@ func expand(tokens []Token, bindings ...interface{}) ([]Token, []interface{}, er
 	return outputTokens, outputBindings, nil
 }
 
+// isArrayType returns whether typ, the type of a typed parameter, is a
+// ClickHouse array type, e.g. "Array(UInt64)".  A sequence bound to such a
+// parameter is bound as is, rather than expanded into a list.
+func isArrayType(typ string) bool {
+	return strings.HasPrefix(strings.TrimSpace(typ), "Array(")
+}
+
 // MustExpand forwards to Expand, except that its return values omit the
 // trailing error and instead MustExpand panics on error.
 func MustExpand(query string, bindings ...interface{}) (string, []interface{}) {
@@ -83,11 +112,24 @@ func MustExpand(query string, bindings ...interface{}) (string, []interface{}) {
 // elements of sequence, and returns true to indicate that sequence is indeed a
 // sequence.  If sequence is not an array or a slice, then unpackSequence
 // returns nil, and returns false to indicate that sequence is not a sequence.
+// Byte slices and implementations of driver.Valuer are single values as far
+// as database/sql is concerned, and so they are not sequences.
 func unpackSequence(sequence interface{}) ([]interface{}, bool) {
-	kind := reflect.TypeOf(sequence).Kind()
+	if sequence == nil {
+		return nil, false
+	}
+	if _, isValuer := sequence.(driver.Valuer); isValuer {
+		return nil, false
+	}
+
+	sequenceType := reflect.TypeOf(sequence)
+	kind := sequenceType.Kind()
 	if kind != reflect.Array && kind != reflect.Slice {
 		return nil, false
 	}
+	if kind == reflect.Slice && sequenceType.Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
 
 	// It's an array or a slice, so unpack it.
 	value := reflect.ValueOf(sequence)
@@ -102,22 +144,33 @@ func unpackSequence(sequence interface{}) ([]interface{}, bool) {
 
 // parameterList returns a slice of tokens that form a SQL list containing
 // implicit positional parameters (question marks), separated by spaces.  count
-// is the number of parameters in the list.  For example,
+// is the number of parameters in the list, and each of the tokens has the
+// position of replaced, the token that the list replaces.  For example,
 //
-//     Render(parameterList(4))
+//     Render(parameterList(4, Token{}))
 //
 // returns the string "(?, ?, ?, ?)".
-func parameterList(count int) []Token {
-	tokens := []Token{{Text: "("}}
+func parameterList(count int, replaced Token) []Token {
+	token := func(kind Kind, text string) Token {
+		return Token{
+			Kind:   kind,
+			Text:   text,
+			Offset: replaced.Offset,
+			Line:   replaced.Line,
+			Column: replaced.Column}
+	}
+
+	tokens := []Token{token(Punctuation, "(")}
 
 	if count != 0 {
-		tokens = append(tokens, Token{Kind: "implicit", Text: "?"})
+		tokens = append(tokens, token(ImplicitParameter, "?"))
 		for count--; count != 0; count-- {
 			tokens = append(tokens,
-				Token{Text: ", "},
-				Token{Kind: "implicit", Text: "?"})
+				token(Punctuation, ","),
+				token(Whitespace, " "),
+				token(ImplicitParameter, "?"))
 		}
 	}
 
-	return append(tokens, Token{Text: ")"})
+	return append(tokens, token(Punctuation, ")"))
 }
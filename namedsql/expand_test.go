@@ -43,3 +43,39 @@ func TestExpandEmptyList(t *testing.T) {
 		t.Error(message)
 	}
 }
+
+func TestExpandBytes(t *testing.T) {
+	blob := []byte("blob")
+	query, bindings, err := Expand("select * from t where x = ? and y in ?", blob, []string{"a"})
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedQuery := "select * from t where x = ? and y in (?)"
+	if query != expectedQuery {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expectedQuery, query)
+	}
+
+	if len(bindings) != 2 || string(bindings[0].([]byte)) != "blob" || bindings[1] != "a" {
+		t.Errorf("bindings not as expected: %v", bindings)
+	}
+}
+
+func TestExpandNil(t *testing.T) {
+	query, bindings, err := Expand("select * from t where x = ?", nil)
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedQuery := "select * from t where x = ?"
+	if query != expectedQuery {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expectedQuery, query)
+	}
+
+	message := sliceDisagreement(sliceCheck{actual: bindings, expected: []interface{}{nil}})
+	if message != "" {
+		t.Error(message)
+	}
+}
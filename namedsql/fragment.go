@@ -0,0 +1,80 @@
+package namedsql
+
+import "reflect"
+
+// Fragment is a piece of a query together with its own bindings.  When a
+// Fragment is bound to a parameter in a query passed to Arrange or
+// ArrangeAndExpand, the parameter is replaced by the Fragment's query, and the
+// Fragment's parameters are arranged using the Fragment's bindings.  For
+// example,
+//
+//	recent := NewFragment("created > @since", map[string]interface{}{"since": since})
+//	query, bindings, err := Arrange(
+//	        "select * from posts where author = @author and @filter",
+//	        map[string]interface{}{"author": author, "filter": recent})
+//
+// leaves query with the value
+//
+//	select * from posts where author = ? and created > ?
+//
+// and bindings with the value
+//
+//	[]interface{}{author, since}
+//
+// A Fragment's parameters are looked up only among the Fragment's own
+// bindings, so its names never clash with those of the enclosing query.  The
+// Fragment's query is inserted as is, so a subquery must include its own
+// parentheses.  A Fragment may be bound within another Fragment, but not
+// within itself, which is a RecursiveFragmentError.
+type Fragment struct {
+	// Query is the text of the fragment, which may contain any parameters
+	// that a query passed to Arrange may contain.
+	Query string
+
+	// Bindings are the named bindings of Query's named parameters.
+	Bindings map[string]interface{}
+
+	// Positionals are the bindings of Query's positional parameters.
+	Positionals []interface{}
+}
+
+// NewFragment returns a Fragment having the specified query, named bindings,
+// and positional bindings.  Its parameters are the same as those of Arrange.
+func NewFragment(query string, bindings map[string]interface{}, positionals ...interface{}) Fragment {
+	return Fragment{Query: query, Bindings: bindings, Positionals: positionals}
+}
+
+// fragmentIdentity identifies a Fragment by its query and by the addresses of
+// its bindings, which a Fragment bound within itself shares with its copies.
+type fragmentIdentity struct {
+	query       string
+	bindings    uintptr
+	positionals uintptr
+}
+
+// identity returns the fragmentIdentity of the fragment.
+func (fragment Fragment) identity() fragmentIdentity {
+	return fragmentIdentity{
+		query:       fragment.Query,
+		bindings:    reflect.ValueOf(fragment.Bindings).Pointer(),
+		positionals: reflect.ValueOf(fragment.Positionals).Pointer()}
+}
+
+// arranging returns whether fragment is already being arranged by options,
+// i.e. whether it's bound within itself.
+func (options Options) arranging(fragment Fragment) bool {
+	identity := fragment.identity()
+	for _, enclosing := range options.fragments {
+		if enclosing == identity {
+			return true
+		}
+	}
+	return false
+}
+
+// arrange returns the tokens and bindings of the fragment, arranged as by
+// options.Arrange.
+func (fragment Fragment) arrange(options Options) ([]Token, []interface{}, error) {
+	options.fragments = append(options.fragments[:len(options.fragments):len(options.fragments)], fragment.identity())
+	return options.arrangeQuery(fragment.Query, fragment.Bindings, fragment.Positionals...)
+}
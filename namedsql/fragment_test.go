@@ -0,0 +1,94 @@
+package namedsql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFragmentBreathing(t *testing.T) {
+	since, author := "2020-04-15", "dgoffredo"
+	recent := NewFragment("created > @since", map[string]interface{}{"since": since})
+	query, bindings, err := Arrange(
+		"select * from posts where author = @author and @filter",
+		map[string]interface{}{"author": author, "filter": recent})
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedQuery := "select * from posts where author = ? and created > ?"
+	if query != expectedQuery {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expectedQuery, query)
+	}
+
+	expectedBindings := []interface{}{author, since}
+	message := sliceDisagreement(sliceCheck{actual: bindings, expected: expectedBindings})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestFragmentNested(t *testing.T) {
+	// The fragments' names clash with the outer query's, but that's fine.
+	inner := NewFragment("select id from tags where name in @x", map[string]interface{}{
+		"x": []string{"a", "b"}})
+	outer := NewFragment("x = ? and id in (@x)", map[string]interface{}{"x": inner}, 2)
+	query, bindings, err := ArrangeAndExpand(
+		"select * from t where x = @x and ?",
+		map[string]interface{}{"x": 1},
+		outer)
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedQuery := "select * from t where x = ? and x = ? and id in " +
+		"(select id from tags where name in (?, ?))"
+	if query != expectedQuery {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expectedQuery, query)
+	}
+
+	expectedBindings := []interface{}{1, 2, "a", "b"}
+	message := sliceDisagreement(sliceCheck{actual: bindings, expected: expectedBindings})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestFragmentError(t *testing.T) {
+	broken := NewFragment("y = @y", nil)
+	_, _, err := Arrange("select * from t where @filter", map[string]interface{}{"filter": broken})
+	if err == nil || !strings.Contains(err.Error(), `"@y"`) || !strings.Contains(err.Error(), `"@filter"`) {
+		t.Errorf("expected an error mentioning both parameters, but got %v", err)
+	}
+}
+
+func TestFragmentRecursive(t *testing.T) {
+	// a is bound within itself.
+	aBindings := map[string]interface{}{}
+	a := NewFragment("x = 1 or @a", aBindings)
+	aBindings["a"] = a
+
+	// b and c are bound within each other.
+	bBindings, cBindings := map[string]interface{}{}, map[string]interface{}{}
+	b := NewFragment("y = 1 or @c", bBindings)
+	c := NewFragment("z = 1 or @b", cBindings)
+	bBindings["c"], cBindings["b"] = c, b
+
+	for _, fragment := range []Fragment{a, b} {
+		_, _, err := Arrange("select * from t where @filter", map[string]interface{}{"filter": fragment})
+		var recursive *RecursiveFragmentError
+		if !errors.As(err, &recursive) {
+			t.Errorf("expected a RecursiveFragmentError, but got %v", err)
+		}
+	}
+
+	// The same fragment may be bound more than once, if not within itself.
+	d := NewFragment("w = @w", map[string]interface{}{"w": 1})
+	query, _, err := Arrange("select * from t where @d and @e", map[string]interface{}{"d": d, "e": d})
+	expectedQuery := "select * from t where w = ? and w = ?"
+	if err != nil || query != expectedQuery {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q\nerror: %v", expectedQuery, query, err)
+	}
+}
@@ -0,0 +1,100 @@
+package namedsql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Identifier is a binding for a table name, column name, or other identifier
+// that can't be bound as an ordinary parameter.  When an Identifier is bound
+// to a parameter in a query passed to Arrange or ArrangeAndExpand, the
+// parameter is replaced by the quoted identifier, rather than by a
+// positional parameter.  For example,
+//
+//	Options{Dialect: MySQL}.Arrange(
+//	        "select * from @table order by @column",
+//	        map[string]interface{}{
+//	                "table":  Ident("events_2026_10"),
+//	                "column": Ident(column, "created", "name")})
+//
+// returns the query
+//
+//	select * from `events_2026_10` order by `created`
+//
+// when column is "created", and an error when column is neither "created" nor
+// "name".
+//
+// The identifier's name may consist only of letters, digits, underscores, and
+// dollar signs, optionally qualified by periods, as in "schema.table", in
+// which case each part is quoted separately.  Names containing any other
+// characters are rejected, never escaped.
+type Identifier struct {
+	// Name is the unquoted identifier, e.g. "events_2026_10".
+	Name string
+
+	// Allowed, if not empty, contains the only values that Name may have.
+	Allowed []string
+}
+
+// Ident returns an Identifier having the specified name.  If any allowed
+// names are specified, then name must be one of them.
+func Ident(name string, allowed ...string) Identifier {
+	return Identifier{Name: name, Allowed: allowed}
+}
+
+// IdentifierQuote is a style of quoting identifiers.
+type IdentifierQuote int
+
+const (
+	// DoubleQuote is the standard SQL style, e.g. "name".  It's the zero
+	// value, and so the default.
+	DoubleQuote IdentifierQuote = iota
+
+	// Backtick is the MySQL style, e.g. `name`.
+	Backtick
+
+	// Brackets is the SQL Server style, e.g. [name].
+	Brackets
+)
+
+// quoteIdentifier returns the identifier quoted in the style of the dialect,
+// or returns an error if the identifier is not allowed or contains unsafe
+// characters.
+func (dialect Dialect) quoteIdentifier(identifier Identifier) (string, error) {
+	if len(identifier.Allowed) != 0 {
+		allowed := false
+		for _, name := range identifier.Allowed {
+			allowed = allowed || name == identifier.Name
+		}
+		if !allowed {
+			return "", fmt.Errorf("identifier %q is not among the allowed names %q",
+				identifier.Name, identifier.Allowed)
+		}
+	}
+
+	parts := strings.Split(identifier.Name, ".")
+	for i, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("identifier %q has an empty part", identifier.Name)
+		}
+
+		for _, char := range part {
+			if !unicode.IsLetter(char) && !unicode.IsDigit(char) && char != '_' && char != '$' {
+				return "", fmt.Errorf("identifier %q contains unsafe character %q",
+					identifier.Name, char)
+			}
+		}
+
+		switch dialect.IdentifierQuote {
+		case Backtick:
+			parts[i] = "`" + part + "`"
+		case Brackets:
+			parts[i] = "[" + part + "]"
+		default:
+			parts[i] = `"` + part + `"`
+		}
+	}
+
+	return strings.Join(parts, "."), nil
+}
@@ -0,0 +1,49 @@
+package namedsql
+
+import "testing"
+
+func TestIdentifierBreathing(t *testing.T) {
+	query := "select * from @table where x = @x order by @column"
+	bindings := map[string]interface{}{
+		"table":  Ident("logs.events_2026_10"),
+		"x":      1,
+		"column": Ident("created", "created", "name")}
+
+	cases := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{Generic, `select * from "logs"."events_2026_10" where x = ? order by "created"`},
+		{MySQL, "select * from `logs`.`events_2026_10` where x = ? order by `created`"},
+		{Dialect{IdentifierQuote: Brackets}, "select * from [logs].[events_2026_10] where x = ? order by [created]"}}
+
+	for _, c := range cases {
+		actual, outputBindings, err := Options{Dialect: c.dialect}.Arrange(query, bindings)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("query not as expected.\nexpected: %q\nactual: %q", c.expected, actual)
+		}
+		message := sliceDisagreement(sliceCheck{actual: outputBindings, expected: []interface{}{1}})
+		if message != "" {
+			t.Error(message)
+		}
+	}
+}
+
+func TestIdentifierRejected(t *testing.T) {
+	for _, identifier := range []Identifier{
+		Ident(`x"; drop table users; --`),
+		Ident("x`y"),
+		Ident("x]y"),
+		Ident("a..b"),
+		Ident(""),
+		Ident("password", "created", "name")} {
+		_, _, err := Arrange("select @column from t", map[string]interface{}{"column": identifier})
+		if err == nil {
+			t.Errorf("expected an error for identifier %q", identifier.Name)
+		}
+	}
+}
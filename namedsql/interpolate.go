@@ -0,0 +1,201 @@
+package namedsql
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BytesLiteral is a style of literal used for []byte values when bindings
+// are interpolated into a query.
+type BytesLiteral int
+
+const (
+	// HexString is the standard SQL style, e.g. X'CAFE', understood by MySQL
+	// and SQLite.  It's the zero value, and so the default.
+	HexString BytesLiteral = iota
+
+	// Bytea is the Postgres style, e.g. '\xcafe'::bytea.
+	Bytea
+
+	// HexNumber is the SQL Server style, e.g. 0xCAFE.
+	HexNumber
+
+	// HexToRaw is the Oracle style, e.g. HEXTORAW('CAFE').
+	HexToRaw
+)
+
+// BoolLiteral is a style of literal used for bool values when bindings are
+// interpolated into a query.
+type BoolLiteral int
+
+const (
+	// TrueFalse is the standard SQL style, i.e. TRUE and FALSE.  It's the
+	// zero value, and so the default.
+	TrueFalse BoolLiteral = iota
+
+	// OneZero is the SQL Server and Oracle style, i.e. 1 and 0, since T-SQL
+	// has no boolean literals, and Oracle has them only as of 23c.
+	OneZero
+)
+
+// TimeLiteral is a style of literal used for time.Time values when bindings
+// are interpolated into a query.
+type TimeLiteral int
+
+const (
+	// OffsetString is a string including the offset from UTC, e.g.
+	// '2020-04-15 13:14:15.5-04:00', understood by Postgres, MySQL, and
+	// SQLite.  It's the zero value, and so the default.
+	OffsetString TimeLiteral = iota
+
+	// LocalString is the SQL Server style, a string in ISO 8601 format
+	// without an offset, e.g. '2020-04-15T13:14:15.5', since datetime and
+	// datetime2 don't accept an offset.  The time is written as it is in its
+	// own location.
+	LocalString
+
+	// Timestamp is the Oracle style, a TIMESTAMP literal including the offset
+	// from UTC, e.g. TIMESTAMP '2020-04-15 13:14:15.5 -04:00'.
+	Timestamp
+)
+
+// timeLayout is the format of time.Time values interpolated into a query.
+const timeLayout = "2006-01-02 15:04:05.999999-07:00"
+
+// localTimeLayout is the format of time.Time values interpolated into a query
+// in the LocalString style.  SQL Server's datetime2 has a precision of 100
+// nanoseconds.
+const localTimeLayout = "2006-01-02T15:04:05.9999999"
+
+// timestampLayout is the format of time.Time values interpolated into a query
+// in the Timestamp style.
+const timestampLayout = "2006-01-02 15:04:05.999999999 -07:00"
+
+// interpolate replaces each implicit positional parameter in tokens with a
+// literal of the corresponding binding, escaped according to the dialect.
+// Each binding is first converted as database/sql would convert it, so
+// driver.Valuer implementations and pointers are supported.  interpolate
+// returns an error if a binding has a type for which there is no literal.
+func (dialect Dialect) interpolate(tokens []Token, bindings []interface{}) ([]Token, error) {
+	outputTokens := make([]Token, len(tokens))
+	bindingIndex := 0
+	for i, token := range tokens {
+		if token.Kind != ImplicitParameter {
+			outputTokens[i] = token
+			continue
+		}
+
+		// There's one binding per implicit parameter, because interpolate
+		// is applied to the output of arrange or expand.
+		literal, err := dialect.literal(bindings[bindingIndex])
+		if err != nil {
+			return nil, fmt.Errorf("unable to interpolate binding %d: %w", bindingIndex+1, err)
+		}
+		outputTokens[i] = Token{
+			Kind:   Literal,
+			Text:   literal,
+			Offset: token.Offset,
+			Line:   token.Line,
+			Column: token.Column}
+		bindingIndex++
+	}
+
+	return outputTokens, nil
+}
+
+// literal returns the SQL literal of value in the dialect.
+func (dialect Dialect) literal(value interface{}) (string, error) {
+	value, err := driver.DefaultParameterConverter.ConvertValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		switch {
+		case dialect.BoolLiteral == OneZero && value:
+			return "1", nil
+		case dialect.BoolLiteral == OneZero:
+			return "0", nil
+		case value:
+			return "TRUE", nil
+		default:
+			return "FALSE", nil
+		}
+	case int64:
+		return number(strconv.FormatInt(value, 10)), nil
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return "", fmt.Errorf("no literal for floating point value %v", value)
+		}
+		return number(strconv.FormatFloat(value, 'g', -1, 64)), nil
+	case string:
+		if dialect.NationalStrings {
+			return "N" + dialect.quoteString(value), nil
+		}
+		return dialect.quoteString(value), nil
+	case []byte:
+		switch dialect.BytesLiteral {
+		case Bytea:
+			return `'\x` + hex.EncodeToString(value) + "'::bytea", nil
+		case HexNumber:
+			return "0x" + strings.ToUpper(hex.EncodeToString(value)), nil
+		case HexToRaw:
+			return "HEXTORAW('" + strings.ToUpper(hex.EncodeToString(value)) + "')", nil
+		default:
+			return "X'" + strings.ToUpper(hex.EncodeToString(value)) + "'", nil
+		}
+	case time.Time:
+		switch dialect.TimeLiteral {
+		case LocalString:
+			return "'" + value.Format(localTimeLayout) + "'", nil
+		case Timestamp:
+			return "TIMESTAMP '" + value.Format(timestampLayout) + "'", nil
+		default:
+			return "'" + value.Format(timeLayout) + "'", nil
+		}
+	default:
+		return "", fmt.Errorf("no literal for value of type %T", value)
+	}
+}
+
+// number returns the formatted number text as a literal.  A negative number
+// is parenthesized, so that its sign can't join a "-" before the parameter,
+// as in "1-@a", to begin a "--" comment.
+func number(text string) string {
+	if strings.HasPrefix(text, "-") {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// quoteString returns text as a single-quoted string literal in the dialect.
+// Single quotes are doubled.  Unless the dialect has standard strings,
+// backslashes and NUL characters are escaped with backslashes as well.
+func (dialect Dialect) quoteString(text string) string {
+	var builder strings.Builder
+	builder.WriteByte('\'')
+	// The special characters are all ASCII, so it's safe to examine bytes
+	// rather than runes, and doing so preserves any invalid UTF-8 as is.
+	for i := 0; i < len(text); i++ {
+		switch char := text[i]; {
+		case char == '\'':
+			builder.WriteString("''")
+		case char == '\\' && !dialect.StandardStrings:
+			builder.WriteString(`\\`)
+		case char == 0 && !dialect.StandardStrings:
+			builder.WriteString(`\0`)
+		default:
+			builder.WriteByte(char)
+		}
+	}
+	builder.WriteByte('\'')
+	return builder.String()
+}
@@ -0,0 +1,104 @@
+package namedsql
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+// money is a driver.Valuer used to test interpolation.
+type money struct{ cents int64 }
+
+func (m money) Value() (driver.Value, error) {
+	return float64(m.cents) / 100, nil
+}
+
+func TestInterpolateBreathing(t *testing.T) {
+	query := "insert into t values (@ids, @name, @blob, @when, @ok, @none, @price, @ratio, @count)"
+	var none *string
+	bindings := map[string]interface{}{
+		"ids":   []int{1, 2},
+		"name":  `O'Brien \ Sons`,
+		"blob":  []byte{0xca, 0xfe},
+		"when":  time.Date(2020, 4, 15, 13, 14, 15, 500000000, time.FixedZone("", -4*60*60)),
+		"ok":    true,
+		"none":  none,
+		"price": money{1999},
+		"ratio": 0.25,
+		"count": uint8(7)}
+
+	cases := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{Generic, `insert into t values ((1, 2), 'O''Brien \\ Sons', X'CAFE', ` +
+			`'2020-04-15 13:14:15.5-04:00', TRUE, NULL, 19.99, 0.25, 7)`},
+		{Postgres, `insert into t values ((1, 2), 'O''Brien \ Sons', '\xcafe'::bytea, ` +
+			`'2020-04-15 13:14:15.5-04:00', TRUE, NULL, 19.99, 0.25, 7)`},
+		{SQLServer, `insert into t values ((1, 2), N'O''Brien \ Sons', 0xCAFE, ` +
+			`'2020-04-15T13:14:15.5', 1, NULL, 19.99, 0.25, 7)`},
+		{Oracle, `insert into t values ((1, 2), 'O''Brien \ Sons', HEXTORAW('CAFE'), ` +
+			`TIMESTAMP '2020-04-15 13:14:15.5 -04:00', 1, NULL, 19.99, 0.25, 7)`}}
+
+	for _, c := range cases {
+		options := Options{Dialect: c.dialect, Interpolate: true}
+		actual, outputBindings, err := options.ArrangeAndExpand(query, bindings)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("query not as expected.\nexpected: %q\nactual: %q", c.expected, actual)
+		}
+		if len(outputBindings) != 0 {
+			t.Errorf("expected no bindings, but got %v", outputBindings)
+		}
+	}
+}
+
+func TestInterpolateSQLServer(t *testing.T) {
+	bindings := map[string]interface{}{
+		"name": "日本",
+		"when": time.Date(2020, 1, 2, 3, 4, 5, 123456700, time.UTC)}
+
+	options := Options{Dialect: SQLServer, Interpolate: true}
+	actual, _, err := options.ArrangeAndExpand("select @name, @when", bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select N'日本', '2020-01-02T03:04:05.1234567'"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+}
+
+func TestInterpolateNegative(t *testing.T) {
+	query := "select 1-@a, 1-@b, @b\nfrom t where id = @id"
+	bindings := map[string]interface{}{"a": -5, "b": -2.5, "id": 1}
+
+	options := Options{Dialect: Postgres, Interpolate: true}
+	actual, _, err := options.ArrangeAndExpand(query, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select 1-(-5), 1-(-2.5), (-2.5)\nfrom t where id = 1"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+}
+
+func TestInterpolateUnsupported(t *testing.T) {
+	options := Options{Interpolate: true}
+	for _, binding := range []interface{}{
+		struct{}{},
+		map[string]int{},
+		[]int{1}, // Arrange doesn't expand
+		uint64(1) << 63} {
+		_, _, err := options.Arrange("select ?", nil, binding)
+		if err == nil {
+			t.Errorf("expected an error interpolating %#v", binding)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package namedsql
+
+import "strconv"
+
+// Kind is the kind of a Token.
+type Kind int
+
+const (
+	// Word is a keyword, an identifier, or a number, e.g. "select", "id", or
+	// "42".  It's the zero value.
+	Word Kind = iota
+
+	// Whitespace is a run of spaces, tabs, and line breaks.
+	Whitespace
+
+	// Punctuation is an operator or other punctuation, e.g. "=", "(", or
+	// "::".
+	Punctuation
+
+	// Comment is a line comment, including its line break, or a block
+	// comment, e.g. "-- note\n" or "/* note */".
+	Comment
+
+	// String is a string literal, e.g. "'it''s'" or "E'it\'s'".
+	String
+
+	// QuotedIdentifier is a quoted identifier, e.g. "`order`" or "[order]".
+	QuotedIdentifier
+
+	// ImplicitParameter is a positional parameter with an implicit position,
+	// e.g. "?" or "%s".
+	ImplicitParameter
+
+	// ExplicitParameter is a positional parameter with an explicit position,
+	// e.g. ":3", "@3", "$3", or "?3".
+	ExplicitParameter
+
+	// NamedParameter is a named parameter, e.g. ":name", "@name", "$name",
+	// `@"order id"`, or "{name:Type}".
+	NamedParameter
+
+	// PythonParameter is a named parameter in Python style, e.g. "%(name)s".
+	PythonParameter
+
+	// Escape is an escaped character that would otherwise begin a parameter,
+	// e.g. "??", "%%", or "\:".
+	Escape
+
+	// OptionalBegin is the beginning of an optional fragment, i.e. "[[".
+	OptionalBegin
+
+	// OptionalEnd is the end of an optional fragment, i.e. "]]".
+	OptionalEnd
+
+	// Unterminated is an unclosed string, quoted identifier, or block
+	// comment, e.g. "'oops", through the end of the query.
+	Unterminated
+
+	// Literal is a literal written in place of a parameter when interpolating
+	// bindings.  Lex never produces it.
+	Literal
+)
+
+// kindNames maps each Kind to the name used for it in String and in the named
+// subpatterns of tokenPatterns.
+var kindNames = map[Kind]string{
+	Word:              "word",
+	Whitespace:        "whitespace",
+	Punctuation:       "punctuation",
+	Comment:           "comment",
+	String:            "string",
+	QuotedIdentifier:  "quotedIdentifier",
+	ImplicitParameter: "implicit",
+	ExplicitParameter: "explicit",
+	NamedParameter:    "named",
+	PythonParameter:   "python",
+	Escape:            "escape",
+	OptionalBegin:     "optionalBegin",
+	OptionalEnd:       "optionalEnd",
+	Unterminated:      "unterminated",
+	Literal:           "literal"}
+
+// kindsByName is the inverse of kindNames.
+var kindsByName = func() map[string]Kind {
+	kinds := make(map[string]Kind, len(kindNames))
+	for kind, name := range kindNames {
+		kinds[name] = kind
+	}
+	return kinds
+}()
+
+// String returns the name of the kind, e.g. "named".
+func (kind Kind) String() string {
+	if name, ok := kindNames[kind]; ok {
+		return name
+	}
+	return "Kind(" + strconv.Itoa(int(kind)) + ")"
+}
+
+// IsParameter returns whether the kind is one of ImplicitParameter,
+// ExplicitParameter, NamedParameter, or PythonParameter.
+func (kind Kind) IsParameter() bool {
+	switch kind {
+	case ImplicitParameter, ExplicitParameter, NamedParameter, PythonParameter:
+		return true
+	default:
+		return false
+	}
+}
+
+// isText returns whether the kind is plain SQL, rather than a parameter,
+// string, quoted identifier, comment, or other special token.
+func (kind Kind) isText() bool {
+	switch kind {
+	case Word, Whitespace, Punctuation:
+		return true
+	default:
+		return false
+	}
+}
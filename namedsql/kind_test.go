@@ -0,0 +1,28 @@
+package namedsql
+
+import "testing"
+
+func TestKindString(t *testing.T) {
+	cases := map[Kind]string{
+		Word:              "word",
+		QuotedIdentifier:  "quotedIdentifier",
+		ImplicitParameter: "implicit",
+		NamedParameter:    "named",
+		Literal:           "literal",
+		Kind(99):          "Kind(99)"}
+	for kind, expected := range cases {
+		if actual := kind.String(); actual != expected {
+			t.Errorf("kind not as expected.\nexpected: %q\nactual: %q", expected, actual)
+		}
+	}
+}
+
+func TestKindIsParameter(t *testing.T) {
+	for kind := range kindNames {
+		expected := kind == ImplicitParameter || kind == ExplicitParameter ||
+			kind == NamedParameter || kind == PythonParameter
+		if actual := kind.IsParameter(); actual != expected {
+			t.Errorf("IsParameter of %v not as expected.\nexpected: %v\nactual: %v", kind, expected, actual)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
 const (
@@ -14,10 +15,20 @@ const (
 	// identifier is a regular expression pattern that matches a letter or an
 	// underscore, followed by letters, underscores, or digits
 	identifier = `(?:\pL|_)(?:\pL|\p{Nd}|_)*`
+
+	// quotedName is a regular expression pattern that matches a nonempty
+	// name enclosed in double quotes, backticks, or brackets, where a doubled
+	// closing delimiter is an escaped one, e.g. "order ""id""" or [a]]b]
+	quotedName = `"(?:[^"]|"")+"|` + "`(?:[^`]|``)+`" + `|\[(?:[^\[\]]|\]\])(?:[^\]]|\]\])*\]`
+
+	// openBrackets is a regular expression pattern that matches a prefix of
+	// a bracketed name, e.g. "[a b" or "[a]", which might be continued by
+	// "]" or "]]"
+	openBrackets = `\[(?:(?:[^\[\]]|\]\])(?:[^\]]|\]\])*\]?)?`
 )
 
-// tokenPatterns is a list of regular expression patterns that will be combined
-// to match tokens.
+// tokenPatterns returns a list of regular expression patterns that will be
+// combined to match tokens in the specified syntax.
 //
 // All subpatterns are non-capturing by using the syntax "(?: ...)" _except_
 // for the following subpatterns that are named using the "(?P<name> ...)"
@@ -25,143 +36,656 @@ const (
 //
 // - implicit (positional parameter with implicit position, i.e. "?")
 // - explicit (positional parameter with explicit position, e.g. ":3")
-// - named    (named parameter in ISO or MySQL style, e.g. ":foo" or "@foo")
+// - named    (named parameter, e.g. ":foo", "@foo", or `@"order id"`)
 // - python   (named parameter in python style, e.g. "%(foo)s")
+// - escape   (escaped sigil, e.g. "??", which is not a parameter)
+// - type     (type annotation of a typed named parameter, e.g. "UInt64")
+// - dollarTag (tag of a dollar-quoted string, e.g. "body" in "$body$")
+// - optionalBegin (beginning of an optional fragment, i.e. "[[")
+// - optionalEnd   (end of an optional fragment, i.e. "]]")
+// - unterminated  (unclosed string, quoted identifier, or comment, e.g. "'oops")
+//
+// The names are those of the Kind of the matched Token (see kindNames),
+// except for "type" and "dollarTag".  The remaining patterns, for comments,
+// strings, and the like, are named after their Kind in their entirety, e.g.
+// "comment".  Text that matches no pattern is divided into words, whitespace,
+// and punctuation by Lex.
+//
+// Each parameter pattern is included only if its syntax is among
+// syntax.parameters.
 //
-// The named subpatterns are what we're after when matching tokens.  Anything
-// else (even no match at all) is considered "other" and has .Kind==""
+// If syntax.streaming is true, then each pattern that can match whitespace,
+// and that has no unterminated form, is preceded by a pattern matching a
+// prefix of it that extends to the end of the text, named "unterminated".
+// This way, a Scanner can tell which tokens might continue past the end of
+// the text read so far, e.g. "[a ]" might continue as "[a ]] b]".
 //
 // The reason there are patterns other than those needed to capture the above
 // is that we must identify tokens that may contain things that look like SQL
 // parameters but that are not, such as comments and quoted strings.
-var tokenPatterns = [...]string{
-	// line comment
-	// -- whatever until the end of the line (or the end of the file)
-	`--[^\n]*(?:\n|$)`,
+func tokenPatterns(syntax syntax) []string {
+	patterns := []string{
+		// line comment
+		// -- whatever until the end of the line (or the end of the file)
+		`(?P<comment>--[^\n]*(?:\n|$))`,
+
+		// block comment
+		// /* whatever until the matching */
+		`(?P<comment>/\*[^*]*\*+(?:[^/*][^*]*\*+)*/)`}
+
+	if syntax.hashComments {
+		patterns = append(patterns,
+			// hash comment (MySQL)
+			// # whatever until the end of the line (or the end of the file)
+			`(?P<comment>#[^\n]*(?:\n|$))`)
+	}
+
+	if syntax.dollarQuotes {
+		patterns = append(patterns,
+			// beginning of a dollar-quoted string (Postgres)
+			// $$, $body$
+			// Lex finds the matching end, e.g. "$body$", since a regular
+			// expression can't.  The "$" can't be part of a word, e.g. in
+			// "a$b$c".
+			`(?P<string>\B\$(?P<dollarTag>(?:`+identifier+`)?)\$)`)
+	}
+
+	if syntax.qQuotes {
+		patterns = append(patterns, `(?P<string>`+qQuotePattern()+`)`)
+		// Unlike the other unterminated patterns, this one would also match
+		// a closed q-quote, and so it must come after the closed pattern.
+		if syntax.streaming {
+			patterns = append(patterns, `(?P<unterminated>\b[nN]?[qQ]'(?:[!-&(-~](?s:.*))?$)`)
+		}
+	}
+
+	// Double quotes enclose identifiers, as in standard SQL, unless they
+	// enclose strings, as in MySQL.
+	doubleQuoted := "quotedIdentifier"
+	if syntax.doubleQuoteStrings {
+		doubleQuoted = "string"
+	}
+
+	if syntax.standardStrings {
+		if syntax.streaming {
+			patterns = append(patterns, `(?P<unterminated>\b[eE]'(?:[^'\\]|\\(?s:.))*\\?$)`)
+		}
+		patterns = append(patterns,
+			// escape string (Postgres), which has backslash escapes
+			// E'escape string, maybe \'with\' escapes'
+			`(?P<string>\b[eE]'(?:[^'\\]|\\(?s:.))*')`)
+
+		patterns = append(patterns,
+			// single-quoted string
+			// 'single-quoted string, maybe ''doubled'' quotes'
+			`(?P<string>'(?:[^']|'')*')`,
+
+			// double-quoted identifier or string
+			// "double-quoted string, maybe ""doubled"" quotes"
+			`(?P<`+doubleQuoted+`>"(?:[^"]|"")*")`,
+
+			// backtick identifier
+			// `backtick identifier, maybe ``doubled`` backticks`
+			"(?P<quotedIdentifier>`(?:[^`]|``)*`)")
+	} else {
+		patterns = append(patterns,
+			// single-quoted string
+			// 'single-quoted string, maybe \'with\' escapes'
+			`(?P<string>'(?:[^'\\]|\\(?s:.))*')`,
+
+			// double-quoted identifier or string
+			// "double-quoted string, maybe \"with\" escapes"
+			`(?P<`+doubleQuoted+`>"(?:[^"\\]|\\(?s:.))*")`,
+
+			// backtick identifier
+			// `backtick identifier, maybe \`with\` escapes`
+			"(?P<quotedIdentifier>`(?:[^`\\\\]|\\\\(?s:.))*`)")
+	}
+
+	if syntax.brackets {
+		if syntax.streaming {
+			patterns = append(patterns, `(?P<unterminated>`+openBrackets+`$)`)
+		}
+		patterns = append(patterns,
+			// bracketed identifier (SQL Server)
+			// [whatever with "]]" as an escaped "]"]
+			// It can't begin with "[", so that "[[" begins an optional
+			// fragment.
+			`(?P<quotedIdentifier>\[(?:[^\[\]]|\]\])(?:[^\]]|\]\])*\])`)
+	}
+
+	if syntax.systemVariables {
+		patterns = append(patterns,
+			// system variable (MySQL) or function (SQL Server), which is
+			// never a parameter
+			// @@sql_mode, @@session.sql_mode
+			`(?P<word>@@`+identifier+`(?:\.`+identifier+`)*)`)
+	}
+
+	if syntax.questionOperators {
+		patterns = append(patterns,
+			// JSONB operators (Postgres), which are not parameters
+			// ?| ?&
+			`(?P<punctuation>\?[|&])`)
+	}
+
+	patterns = append(patterns,
+		// escaped sigil, which is not a parameter
+		// \:name, \@name, \$1, \?, \%s, \{name:Type}, \[[
+		`\\(?P<escape>[?:@$%{\[])`,
+
+		// type cast (Postgres), which is not a parameter
+		// ::text
+		`(?P<punctuation>::)`,
+
+		// escaped question mark, which is not a parameter
+		// ??
+		`\?(?P<escape>\?)`,
+
+		// escaped percent sign (Python), which is not a parameter
+		// %%
+		`%(?P<escape>%)`)
+
+	parameters := syntax.parameters
+	if parameters&QuestionNumberParameters != 0 {
+		patterns = append(patterns,
+			// numbered explicit positional parameter (SQLite)
+			// ?3
+			`\?(?P<explicit>`+natural+`)`)
+	}
+
+	if parameters&QuestionParameters != 0 {
+		patterns = append(patterns,
+			// implicit positional parameter
+			// ?
+			`(?P<implicit>\?)`)
+	}
+
+	if parameters&PercentParameters != 0 {
+		patterns = append(patterns,
+			// python-style implicit positional parameter
+			// %s
+			`(?P<implicit>%s)\b`)
+	}
+
+	if sigils := sigilClass(parameters, DollarNumberParameters, AtNumberParameters, ColonNumberParameters); sigils != "" {
+		patterns = append(patterns,
+			// explicit positional parameter
+			// :4, :5, @1, @0 (zero is an invalid index, but is a valid Token)
+			sigils+`(?P<explicit>`+natural+`)`)
+	}
+
+	if sigils := sigilClass(parameters, DollarNameParameters, AtNameParameters, ColonNameParameters); sigils != "" {
+		if syntax.streaming {
+			patterns = append(patterns,
+				`(?P<unterminated>`+sigils+`(?:"(?:[^"]|"")*"?|`+"`(?:[^`]|``)*`?"+`|`+openBrackets+`)$)`)
+		}
+		patterns = append(patterns,
+			// named parameter
+			// @userID, :name, $name, @"order id", :[weird-name]
+			sigils+`(?P<named>`+identifier+`|`+quotedName+`)`)
+	}
+
+	if parameters&PythonParameters != 0 {
+		patterns = append(patterns,
+			// python-style named parameter
+			// %(foo)s, %(bar)s
+			`%\((?P<python>`+identifier+`)\)s`)
+	}
+
+	if parameters&BraceParameters != 0 {
+		if syntax.streaming {
+			patterns = append(patterns, `(?P<unterminated>\{(?:`+identifier+`(?::[^{}]*)?)?$)`)
+		}
+		patterns = append(patterns,
+			// typed named parameter (ClickHouse)
+			// {id:UInt64}, {ids:Array(String)}
+			`\{(?P<named>`+identifier+`):(?P<type>[^{}]+)\}`)
+	}
+
+	return append(patterns,
+		// optional fragment delimiters
+		// [[ and color = @color ]]
+		`(?P<optionalBegin>\[\[)`,
+		`(?P<optionalEnd>\]\])`,
+
+		// unterminated string, quoted identifier, or block comment
+		// 'whatever until the end of the query
+		// These come last so that they match only if the terminated forms above
+		// didn't.  The opening delimiter is captured.
+		"(?P<unterminated>'|\"|`|/\\*)(?s:.*)")
+}
+
+// qQuotePattern returns the pattern of an alternatively quoted string
+// (Oracle), e.g.
+//
+//	q'[whatever until the matching ]'
+//	q'!whatever until the next !'
+//
+// where the brackets can be any of "()", "[]", "{}", or "<>", and "!" can be
+// any other character.  Go's regular expressions can't refer back to the
+// opening delimiter, so there is an alternative for each.
+func qQuotePattern() string {
+	var alternatives []string
+	for _, pair := range []string{"()", "[]", "{}", "<>"} {
+		alternatives = append(alternatives,
+			regexp.QuoteMeta(pair[:1])+`(?s:.*?)`+regexp.QuoteMeta(pair[1:]))
+	}
+	for delimiter := '!'; delimiter <= '~'; delimiter++ {
+		if strings.ContainsRune("'([{<", delimiter) {
+			continue
+		}
+		quoted := regexp.QuoteMeta(string(delimiter))
+		alternatives = append(alternatives, quoted+`(?s:.*?)`+quoted)
+	}
+	return `\b[nN]?[qQ]'(?:` + strings.Join(alternatives, "|") + `)'`
+}
+
+// ParameterSyntax is a set of syntaxes for parameters, combined using the "|"
+// operator, e.g. ColonNameParameters|QuestionParameters.
+type ParameterSyntax uint
+
+const (
+	// QuestionParameters are implicit positional parameters, e.g. "?".
+	QuestionParameters ParameterSyntax = 1 << iota
 
-	// block comment
-	// /* whatever until the matching */
-	`/\*(?:[^*]|\*[^/])*\*/`,
+	// PercentParameters are implicit positional parameters in Python style,
+	// e.g. "%s".
+	PercentParameters
 
-	// single-quoted string
-	// 'single-quoted string, maybe \'with\' escapes'
-	`'(?:[^'\\]|\\.)*'`,
+	// DollarNumberParameters are explicit positional parameters, e.g. "$1".
+	DollarNumberParameters
 
-	// double-quoted string
-	// "double-quoted string, maybe \"with\" escapes"
-	`"(?:[^"\\]|\\.)*"`,
+	// ColonNumberParameters are explicit positional parameters, e.g. ":1".
+	ColonNumberParameters
 
-	// backtick string
-	// `backtick string, maybe \`with\` escapes`
-	"`(?:[^`\\\\]|\\\\.)*`",
+	// AtNumberParameters are explicit positional parameters, e.g. "@1".
+	AtNumberParameters
 
-	// implicit positional parameter
-	// ?
-	`(?P<implicit>\?)`,
+	// QuestionNumberParameters are explicit positional parameters, e.g. "?1".
+	QuestionNumberParameters
 
-	// explicit positional parameter
-	// :4, :5, @1, @0 (zero is an invalid index, but is a valid Token)
-	`[$@:](?P<explicit>` + natural + `)`,
+	// ColonNameParameters are named parameters, e.g. ":name".
+	ColonNameParameters
 
-	// named parameter
-	// @userID, :name, %(python_style)s
-	`[@:](?P<named>` + identifier + `)`,
+	// AtNameParameters are named parameters, e.g. "@name".
+	AtNameParameters
 
-	// python-style named parameter
-	// %(foo)s, %(bar)s
-	`%\((?P<python>` + identifier + `)\)s`}
+	// DollarNameParameters are named parameters, e.g. "$name".
+	DollarNameParameters
+
+	// PythonParameters are named parameters in Python style, e.g.
+	// "%(name)s".
+	PythonParameters
+
+	// BraceParameters are typed named parameters, e.g. "{name:UInt64}".
+	BraceParameters
+
+	// DefaultParameters are the syntaxes recognized by the zero Lexer:
+	// all of them except QuestionNumberParameters and DollarNameParameters.
+	DefaultParameters = QuestionParameters | PercentParameters |
+		DollarNumberParameters | ColonNumberParameters | AtNumberParameters |
+		ColonNameParameters | AtNameParameters | PythonParameters |
+		BraceParameters
+)
+
+// Lexer configures which syntaxes for parameters are recognized when lexing a
+// query.  Text that would be a parameter in a syntax that isn't selected is
+// instead plain text, e.g. "@name" if AtNameParameters isn't selected.  The
+// zero value of Lexer recognizes the DefaultParameters.
+type Lexer struct {
+	// Parameters are the syntaxes recognized, or DefaultParameters if zero.
+	Parameters ParameterSyntax
+}
+
+// parameters returns the syntaxes recognized by lexer.
+func (lexer Lexer) parameters() ParameterSyntax {
+	if lexer.Parameters == 0 {
+		return DefaultParameters
+	}
+	return lexer.Parameters
+}
+
+// sigilClass returns a regular expression pattern that matches the sigil of
+// each of dollar, at, and colon that's in parameters, e.g. "[@:]" if at and
+// colon are in parameters.  It returns "" if none of them are.
+func sigilClass(parameters ParameterSyntax, dollar, at, colon ParameterSyntax) string {
+	sigils := ""
+	if parameters&dollar != 0 {
+		sigils += "$"
+	}
+	if parameters&at != 0 {
+		sigils += "@"
+	}
+	if parameters&colon != 0 {
+		sigils += ":"
+	}
+	if sigils == "" {
+		return ""
+	}
+	return "[" + sigils + "]"
+}
+
+// syntax is the part of a Dialect that determines how queries are lexed.
+// It's comparable, so that it can key the cache of compiled regular
+// expressions.
+type syntax struct {
+	standardStrings    bool
+	hashComments       bool
+	systemVariables    bool
+	brackets           bool
+	qQuotes            bool
+	questionOperators  bool
+	doubleQuoteStrings bool
+	dollarQuotes       bool
+	parameters         ParameterSyntax
+
+	// streaming is whether the text lexed might be continued, as when it's
+	// read by a Scanner.  See tokenPatterns.
+	streaming bool
+}
 
 var regexpMutex sync.Mutex
-var compiledRegexp *regexp.Regexp
+var compiledRegexps = map[syntax]*regexp.Regexp{}
 
-// tokenRegexp returns a pointer to a singleton instance of a compiled regular
-// expression (compiledRegexp) used to match tokens.  It uses regexpMutex to
-// prevent concurrent compilation of the regular expression.
-func tokenRegexp() *regexp.Regexp {
+// tokenRegexp returns a pointer to a singleton instance, per syntax, of a
+// compiled regular expression (in compiledRegexps) used to match tokens.  It
+// uses regexpMutex to prevent concurrent compilation of the regular
+// expression.
+func tokenRegexp(syntax syntax) *regexp.Regexp {
 	regexpMutex.Lock()
 	defer regexpMutex.Unlock()
 
-	if compiledRegexp != nil {
-		return compiledRegexp
+	if compiled, ok := compiledRegexps[syntax]; ok {
+		return compiled
 	}
 
 	// It's not compiled yet, so we have to compile it.
-	clauses := make([]string, len(tokenPatterns))
-	for i, pattern := range tokenPatterns {
+	patterns := tokenPatterns(syntax)
+	clauses := make([]string, len(patterns))
+	for i, pattern := range patterns {
 		// wrap the pattern so that it's a non-capturing subpattern
 		clauses[i] = "(?:" + pattern + ")"
 	}
 
 	pattern := strings.Join(clauses, "|")
-	compiledRegexp = regexp.MustCompile(pattern)
+	compiled := regexp.MustCompile(pattern)
+	compiledRegexps[syntax] = compiled
 
-	return compiledRegexp
+	return compiled
 }
 
 // Token is a chunk of a SQL query, possibly containing information about a
 // SQL parameter therein.
 type Token struct {
-	// kind is one of the names from the named subpatterns in tokenPatterns,
-	// e.g. "explicit", or "" if it didn't match a parameter-related pattern
-	Kind string
+	// kind is what the Token is, e.g. a Comment or an ExplicitParameter
+	Kind Kind
 
 	// text is the full extent of the Token in the source SQL, e.g. ":23"
 	Text string
 
 	// inside is the part of the Token relevant to interpretation, e.g. "foo"
-	// in "@foo", or empty if no interpretation is necessary
+	// in "@foo", or empty if no interpretation is necessary.  A quoted name
+	// is unquoted, e.g. `order "id"` in `@"order ""id"""`
 	Inside string
+
+	// type is the type annotation of a typed named parameter, e.g. "UInt64"
+	// in "{id:UInt64}", or empty otherwise
+	Type string
+
+	// offset is the zero-based byte offset of the Token in the source SQL.
+	// Tokens that replace other tokens, such as the "?" that Arrange puts in
+	// place of a named parameter, have the offset of the Token they replace.
+	Offset int
+
+	// line is the one-based line number of the Token in the source SQL
+	Line int
+
+	// column is the one-based column number, counted in characters, of the
+	// Token within its line
+	Column int
 }
 
 // Lex returns a slice of tokens lexed (i.e. read, scanned) from query.  It is
-// the opposite of Render.
+// the opposite of Render.  Lex follows the conventions of the Generic dialect.
 func Lex(query string) []Token {
+	return Generic.Lex(query)
+}
+
+// Lex returns a slice of tokens lexed from query, recognizing only the
+// parameter syntaxes selected by lexer.  Otherwise, Lex follows the
+// conventions of the Generic dialect.
+func (lexer Lexer) Lex(query string) []Token {
+	return Dialect{Lexer: lexer}.Lex(query)
+}
+
+// Lex returns a slice of tokens lexed from query according to the
+// conventions of the dialect.  For example, if the dialect has standard
+// strings, then a backslash within a string does not escape the following
+// quote.
+func (dialect Dialect) Lex(query string) []Token {
+	return lex(tokenRegexp(dialect.syntax(false)), query)
+}
+
+// syntax returns the syntax of the dialect, for lexing text that might be
+// continued if streaming is true.
+func (dialect Dialect) syntax(streaming bool) syntax {
+	return syntax{
+		standardStrings:    dialect.StandardStrings,
+		hashComments:       dialect.HashComments,
+		systemVariables:    dialect.UserVariables || dialect.LocalVariables,
+		brackets:           dialect.BracketIdentifiers,
+		qQuotes:            dialect.QQuotes,
+		questionOperators:  dialect.QuestionOperators,
+		doubleQuoteStrings: dialect.DoubleQuoteStrings,
+		dollarQuotes:       dialect.DollarQuotes,
+		parameters:         dialect.Lexer.parameters(),
+		streaming:          streaming}
+}
+
+// isWordByte returns whether b is a word character as understood by "\b" in a
+// regular expression, i.e. an ASCII letter, digit, or underscore.
+func isWordByte(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || b == '_'
+}
+
+// lex returns the tokens of query, as matched by regexp, a regular expression
+// returned by tokenRegexp.
+func lex(regexp *regexp.Regexp, query string) []Token {
 	var tokens = []Token{}
-	regexp := tokenRegexp()
 	// index of one-past-the-last-byte of the previous Token in query
 	var previousTokenEnd = 0
+	// indices of the subpatterns that aren't kinds
+	var typeIndex = regexp.SubexpIndex("type")
+	var dollarTagIndex = regexp.SubexpIndex("dollarTag")
 
-	for _, match := range regexp.FindAllStringSubmatchIndex(query, -1) {
-		begin, end := match[0], match[1]
-
-		// If we skipped some text (no match in between), then consider the
-		// skipped text to be a Token of other ("") kind.  For example, in:
-		//
-		//     /* here's a comment */ select * from foo where bar = ?;
-		//
-		// we will initially match the comment "/* here's a comment */" and
-		// then match the implicit positional parameter "?".  The text in
-		// between, " select * from foo where bar = ", is an other ("") Token.
-		if begin != previousTokenEnd {
-			tokens = append(tokens, Token{Text: query[previousTokenEnd:begin]})
+	// Matches are found a batch at a time, since matching begins again after
+	// each dollar-quoted string, whose end isn't matched by regexp.  Finding
+	// all of the remaining matches each time would take quadratic time, so
+	// the batch begins small after each dollar-quoted string, and doubles
+	// each time it's used up.
+	batchSize := 1
+	for position := 0; position < len(query); {
+		matches := regexp.FindAllStringSubmatchIndex(query[position:], batchSize)
+		resume := len(query)
+		if len(matches) == batchSize {
+			// There might be more matches.  Matching can begin again only
+			// after a match that doesn't end in a word character, since "\b"
+			// at the beginning of query[resume:] must mean what it does
+			// within query.  If there's no such match, try a bigger batch.
+			last := len(matches) - 1
+			for last >= 0 && isWordByte(query[position+matches[last][1]-1]) {
+				last--
+			}
+			if last < 0 {
+				batchSize *= 2
+				continue
+			}
+			matches = matches[:last+1]
+			resume = position + matches[last][1]
+			batchSize *= 2
 		}
 
-		// Determine which, if any, of the named subpatterns matched.  If none
-		// matched, emit a Token of other ("") kind.
-		submatchIndices := match[2:]
-		currentToken := Token{Text: query[begin:end]}
-		for i, subpatternName := range regexp.SubexpNames()[1:] {
-			subBegin, subEnd := submatchIndices[2*i], submatchIndices[2*i+1]
-			if subBegin == -1 {
-				continue // this subpattern didn't match
+		for _, match := range matches {
+			for i := range match {
+				if match[i] != -1 {
+					match[i] += position
+				}
 			}
+			begin, end := match[0], match[1]
 
-			currentToken.Kind = subpatternName
-			currentToken.Inside = query[subBegin:subEnd]
-			break // at most one subpattern will match (I claim)
-		}
+			// If we skipped some text (no match in between), then divide the
+			// skipped text into words, whitespace, and punctuation.  For
+			// example, in:
+			//
+			//     /* here's a comment */ select * from foo where bar = ?;
+			//
+			// we will initially match the comment "/* here's a comment */"
+			// and then match the implicit positional parameter "?".  The text
+			// in between, " select * from foo where bar = ", is divided into
+			// " ", "select", " ", "*", and so on.
+			if begin != previousTokenEnd {
+				tokens = appendText(tokens, query[previousTokenEnd:begin], previousTokenEnd)
+			}
+
+			// Determine which of the named subpatterns matched.
+			submatchIndices := match[2:]
+			currentToken := Token{Text: query[begin:end], Offset: begin}
+			for i, subpatternName := range regexp.SubexpNames()[1:] {
+				subBegin, subEnd := submatchIndices[2*i], submatchIndices[2*i+1]
+				if subBegin == -1 {
+					continue // this subpattern didn't match
+				}
+				if subpatternName == "type" || subpatternName == "dollarTag" {
+					continue // it's not a kind, and is handled below
+				}
+
+				currentToken.Kind = kindsByName[subpatternName]
+				switch currentToken.Kind {
+				case Comment, String, QuotedIdentifier, Word, Punctuation:
+					// The subpattern is the entire token, which needs no
+					// interpretation.
+				case NamedParameter:
+					currentToken.Inside = unquoteName(query[subBegin:subEnd])
+				default:
+					currentToken.Inside = query[subBegin:subEnd]
+				}
+				break // at most one subpattern will match (I claim)
+			}
 
-		tokens = append(tokens, currentToken)
-		previousTokenEnd = end
+			// A typed parameter also has its type annotation, unless typed
+			// parameters aren't recognized at all.
+			if typeIndex != -1 && match[2*typeIndex] != -1 {
+				currentToken.Type = query[match[2*typeIndex]:match[2*typeIndex+1]]
+			}
+
+			// A dollar-quoted string extends to the next occurrence of its
+			// delimiter, e.g. "$body$", or else is unterminated.
+			if dollarTagIndex != -1 && match[2*dollarTagIndex] != -1 {
+				delimiter := currentToken.Text
+				if length := strings.Index(query[end:], delimiter); length != -1 {
+					end += length + len(delimiter)
+				} else {
+					currentToken.Kind = Unterminated
+					currentToken.Inside = delimiter
+					end = len(query)
+				}
+				currentToken.Text = query[begin:end]
+				tokens = append(tokens, currentToken)
+				previousTokenEnd = end
+				resume = end
+				batchSize = 1
+				break
+			}
+
+			tokens = append(tokens, currentToken)
+			previousTokenEnd = end
+		}
+		position = resume
 	}
 
 	if previousTokenEnd != len(query) {
-		tokens = append(tokens, Token{Text: query[previousTokenEnd:]})
+		tokens = appendText(tokens, query[previousTokenEnd:], previousTokenEnd)
 	}
 
+	locateTokens(tokens)
 	return tokens
 }
 
+// textPattern matches a word, whitespace, or a punctuation character.
+var textPattern = regexp.MustCompile(`((?:\pL|\p{Nd}|_)(?:\pL|\p{Nd}|[_$])*)|((?:\s|\pZ)+)|(?s:.)`)
+
+// appendText appends to tokens the words, whitespace, and punctuation of text,
+// which begins at offset, and returns the result.  Each punctuation character
+// is a separate Token.
+func appendText(tokens []Token, text string, offset int) []Token {
+	for _, match := range textPattern.FindAllStringSubmatchIndex(text, -1) {
+		token := Token{Text: text[match[0]:match[1]], Offset: offset + match[0]}
+		switch {
+		case match[2] != -1:
+			token.Kind = Word
+		case match[4] != -1:
+			token.Kind = Whitespace
+		default:
+			token.Kind = Punctuation
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// locateTokens fills in the line and column of each of tokens, which are the
+// tokens of an entire query, in order.
+func locateTokens(tokens []Token) {
+	line, column := 1, 1
+	for i := range tokens {
+		tokens[i].Line, tokens[i].Column = line, column
+		text := tokens[i].Text
+		if lastBreak := strings.LastIndexByte(text, '\n'); lastBreak != -1 {
+			line += strings.Count(text, "\n")
+			column = utf8.RuneCountInString(text[lastBreak+1:]) + 1
+		} else {
+			column += utf8.RuneCountInString(text)
+		}
+	}
+}
+
+// unquoteName returns the name within a quoted parameter name, e.g. `order
+// "id"` in `"order ""id"""`, or returns name unchanged if it isn't quoted.
+func unquoteName(name string) string {
+	switch name[0] {
+	case '"':
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	case '`':
+		return strings.ReplaceAll(name[1:len(name)-1], "``", "`")
+	case '[':
+		return strings.ReplaceAll(name[1:len(name)-1], "]]", "]")
+	default:
+		return name
+	}
+}
+
+// sliceToken returns the part of token whose text is token.Text[begin:end],
+// having the same kind, and having the position of that text.
+func sliceToken(token Token, begin int, end int) Token {
+	slice := Token{
+		Kind:   token.Kind,
+		Text:   token.Text[begin:end],
+		Offset: token.Offset + begin,
+		Line:   token.Line,
+		Column: token.Column}
+
+	before := token.Text[:begin]
+	if lastBreak := strings.LastIndexByte(before, '\n'); lastBreak != -1 {
+		slice.Line += strings.Count(before, "\n")
+		slice.Column = utf8.RuneCountInString(before[lastBreak+1:]) + 1
+	} else {
+		slice.Column += utf8.RuneCountInString(before)
+	}
+
+	return slice
+}
+
 // Render returns the concatenation of all of the text in tokens.  It is the
 // opposite of Lex.
 func Render(tokens []Token) string {
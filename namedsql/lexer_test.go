@@ -52,18 +52,18 @@ func TestLexerLexBreathing1(t *testing.T) {
 	// Here's an arbitrary test that I used as I was writing Lex.
 	query := " -- foo\n/*bar*/NONSENSE'baz'\"buzz\"`fizz`?@1$2:wakka%(hah)s"
 	expected := []Token{
-		{Text: " "},        // non-matching
-		{Text: "-- foo\n"}, // line comment
-		{Text: "/*bar*/"},  // block comment
-		{Text: "NONSENSE"}, // unmatched
-		{Text: "'baz'"},    // single-quoted string
-		{Text: `"buzz"`},   // double-quoted string
-		{Text: "`fizz`"},   // back-quoted string
-		{Kind: "implicit", Text: "?", Inside: "?"},       // implicit positional parameter
-		{Kind: "explicit", Text: "@1", Inside: "1"},      // explicit positional parameter
-		{Kind: "explicit", Text: "$2", Inside: "2"},      // explicit positional parameter
-		{Kind: "named", Text: ":wakka", Inside: "wakka"}, // named parameter
-		{Kind: "python", Text: "%(hah)s", Inside: "hah"}} // python-style named parameter
+		{Kind: Whitespace, Text: " ", Offset: 0, Line: 1, Column: 1},
+		{Kind: Comment, Text: "-- foo\n", Offset: 1, Line: 1, Column: 2},
+		{Kind: Comment, Text: "/*bar*/", Offset: 8, Line: 2, Column: 1},
+		{Kind: Word, Text: "NONSENSE", Offset: 15, Line: 2, Column: 8},
+		{Kind: String, Text: "'baz'", Offset: 23, Line: 2, Column: 16},
+		{Kind: QuotedIdentifier, Text: `"buzz"`, Offset: 28, Line: 2, Column: 21},
+		{Kind: QuotedIdentifier, Text: "`fizz`", Offset: 34, Line: 2, Column: 27},
+		{Kind: ImplicitParameter, Text: "?", Inside: "?", Offset: 40, Line: 2, Column: 33},
+		{Kind: ExplicitParameter, Text: "@1", Inside: "1", Offset: 41, Line: 2, Column: 34},
+		{Kind: ExplicitParameter, Text: "$2", Inside: "2", Offset: 43, Line: 2, Column: 36},
+		{Kind: NamedParameter, Text: ":wakka", Inside: "wakka", Offset: 45, Line: 2, Column: 38},
+		{Kind: PythonParameter, Text: "%(hah)s", Inside: "hah", Offset: 51, Line: 2, Column: 44}}
 	tokens := Lex(query)
 	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
 	if message != "" {
@@ -80,13 +80,52 @@ from bazz
 where foo = @some_damned_thing
   and bar in @more_things;`
 	expected := []Token{
-		{Text: "-- Here's a more realistic example.\n"}, // line comment
-		{Text: "select foo, bar\nfrom bazz\n  inner join hah " +
-			"on bazz.id = hah.id\nwhere foo = "}, // non-matching
-		{Kind: "named", Text: "@some_damned_thing", Inside: "some_damned_thing"}, // named parameter
-		{Text: "\n  and bar in "},                                    // non-matching
-		{Kind: "named", Text: "@more_things", Inside: "more_things"}, // named parameter
-		{Text: ";"}} // non-matching
+		{Kind: Comment, Text: "-- Here's a more realistic example.\n", Offset: 0, Line: 1, Column: 1},
+		{Kind: Word, Text: "select", Offset: 36, Line: 2, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 42, Line: 2, Column: 7},
+		{Kind: Word, Text: "foo", Offset: 43, Line: 2, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 46, Line: 2, Column: 11},
+		{Kind: Whitespace, Text: " ", Offset: 47, Line: 2, Column: 12},
+		{Kind: Word, Text: "bar", Offset: 48, Line: 2, Column: 13},
+		{Kind: Whitespace, Text: "\n", Offset: 51, Line: 2, Column: 16},
+		{Kind: Word, Text: "from", Offset: 52, Line: 3, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 56, Line: 3, Column: 5},
+		{Kind: Word, Text: "bazz", Offset: 57, Line: 3, Column: 6},
+		{Kind: Whitespace, Text: "\n  ", Offset: 61, Line: 3, Column: 10},
+		{Kind: Word, Text: "inner", Offset: 64, Line: 4, Column: 3},
+		{Kind: Whitespace, Text: " ", Offset: 69, Line: 4, Column: 8},
+		{Kind: Word, Text: "join", Offset: 70, Line: 4, Column: 9},
+		{Kind: Whitespace, Text: " ", Offset: 74, Line: 4, Column: 13},
+		{Kind: Word, Text: "hah", Offset: 75, Line: 4, Column: 14},
+		{Kind: Whitespace, Text: " ", Offset: 78, Line: 4, Column: 17},
+		{Kind: Word, Text: "on", Offset: 79, Line: 4, Column: 18},
+		{Kind: Whitespace, Text: " ", Offset: 81, Line: 4, Column: 20},
+		{Kind: Word, Text: "bazz", Offset: 82, Line: 4, Column: 21},
+		{Kind: Punctuation, Text: ".", Offset: 86, Line: 4, Column: 25},
+		{Kind: Word, Text: "id", Offset: 87, Line: 4, Column: 26},
+		{Kind: Whitespace, Text: " ", Offset: 89, Line: 4, Column: 28},
+		{Kind: Punctuation, Text: "=", Offset: 90, Line: 4, Column: 29},
+		{Kind: Whitespace, Text: " ", Offset: 91, Line: 4, Column: 30},
+		{Kind: Word, Text: "hah", Offset: 92, Line: 4, Column: 31},
+		{Kind: Punctuation, Text: ".", Offset: 95, Line: 4, Column: 34},
+		{Kind: Word, Text: "id", Offset: 96, Line: 4, Column: 35},
+		{Kind: Whitespace, Text: "\n", Offset: 98, Line: 4, Column: 37},
+		{Kind: Word, Text: "where", Offset: 99, Line: 5, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 104, Line: 5, Column: 6},
+		{Kind: Word, Text: "foo", Offset: 105, Line: 5, Column: 7},
+		{Kind: Whitespace, Text: " ", Offset: 108, Line: 5, Column: 10},
+		{Kind: Punctuation, Text: "=", Offset: 109, Line: 5, Column: 11},
+		{Kind: Whitespace, Text: " ", Offset: 110, Line: 5, Column: 12},
+		{Kind: NamedParameter, Text: "@some_damned_thing", Inside: "some_damned_thing", Offset: 111, Line: 5, Column: 13},
+		{Kind: Whitespace, Text: "\n  ", Offset: 129, Line: 5, Column: 31},
+		{Kind: Word, Text: "and", Offset: 132, Line: 6, Column: 3},
+		{Kind: Whitespace, Text: " ", Offset: 135, Line: 6, Column: 6},
+		{Kind: Word, Text: "bar", Offset: 136, Line: 6, Column: 7},
+		{Kind: Whitespace, Text: " ", Offset: 139, Line: 6, Column: 10},
+		{Kind: Word, Text: "in", Offset: 140, Line: 6, Column: 11},
+		{Kind: Whitespace, Text: " ", Offset: 142, Line: 6, Column: 13},
+		{Kind: NamedParameter, Text: "@more_things", Inside: "more_things", Offset: 143, Line: 6, Column: 14},
+		{Kind: Punctuation, Text: ";", Offset: 155, Line: 6, Column: 26}}
 	tokens := Lex(query)
 	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
 	if message != "" {
@@ -106,12 +145,576 @@ func TestLexerLexEmpty(t *testing.T) {
 }
 
 func TestLexerLexBoring(t *testing.T) {
-	// Nothing matches => one token
-	query := "There's no parameter binding, strings, comments, or anything."
-	expected := []Token{Token{Text: query}}
+	// Nothing matches => only words, whitespace, and punctuation
+	query := "There is no parameter binding, strings, comments, or anything."
+	expected := []Token{
+		{Kind: Word, Text: "There", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 5, Line: 1, Column: 6},
+		{Kind: Word, Text: "is", Offset: 6, Line: 1, Column: 7},
+		{Kind: Whitespace, Text: " ", Offset: 8, Line: 1, Column: 9},
+		{Kind: Word, Text: "no", Offset: 9, Line: 1, Column: 10},
+		{Kind: Whitespace, Text: " ", Offset: 11, Line: 1, Column: 12},
+		{Kind: Word, Text: "parameter", Offset: 12, Line: 1, Column: 13},
+		{Kind: Whitespace, Text: " ", Offset: 21, Line: 1, Column: 22},
+		{Kind: Word, Text: "binding", Offset: 22, Line: 1, Column: 23},
+		{Kind: Punctuation, Text: ",", Offset: 29, Line: 1, Column: 30},
+		{Kind: Whitespace, Text: " ", Offset: 30, Line: 1, Column: 31},
+		{Kind: Word, Text: "strings", Offset: 31, Line: 1, Column: 32},
+		{Kind: Punctuation, Text: ",", Offset: 38, Line: 1, Column: 39},
+		{Kind: Whitespace, Text: " ", Offset: 39, Line: 1, Column: 40},
+		{Kind: Word, Text: "comments", Offset: 40, Line: 1, Column: 41},
+		{Kind: Punctuation, Text: ",", Offset: 48, Line: 1, Column: 49},
+		{Kind: Whitespace, Text: " ", Offset: 49, Line: 1, Column: 50},
+		{Kind: Word, Text: "or", Offset: 50, Line: 1, Column: 51},
+		{Kind: Whitespace, Text: " ", Offset: 52, Line: 1, Column: 53},
+		{Kind: Word, Text: "anything", Offset: 53, Line: 1, Column: 54},
+		{Kind: Punctuation, Text: ".", Offset: 61, Line: 1, Column: 62}}
+	tokens := Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexOptional(t *testing.T) {
+	// Optional fragment delimiters are recognized outside of strings.
+	query := "where 1=1 [[ and x = '[[' ]] and a[b[1]]"
+	expected := []Token{
+		{Kind: Word, Text: "where", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 5, Line: 1, Column: 6},
+		{Kind: Word, Text: "1", Offset: 6, Line: 1, Column: 7},
+		{Kind: Punctuation, Text: "=", Offset: 7, Line: 1, Column: 8},
+		{Kind: Word, Text: "1", Offset: 8, Line: 1, Column: 9},
+		{Kind: Whitespace, Text: " ", Offset: 9, Line: 1, Column: 10},
+		{Kind: OptionalBegin, Text: "[[", Inside: "[[", Offset: 10, Line: 1, Column: 11},
+		{Kind: Whitespace, Text: " ", Offset: 12, Line: 1, Column: 13},
+		{Kind: Word, Text: "and", Offset: 13, Line: 1, Column: 14},
+		{Kind: Whitespace, Text: " ", Offset: 16, Line: 1, Column: 17},
+		{Kind: Word, Text: "x", Offset: 17, Line: 1, Column: 18},
+		{Kind: Whitespace, Text: " ", Offset: 18, Line: 1, Column: 19},
+		{Kind: Punctuation, Text: "=", Offset: 19, Line: 1, Column: 20},
+		{Kind: Whitespace, Text: " ", Offset: 20, Line: 1, Column: 21},
+		{Kind: String, Text: "'[['", Offset: 21, Line: 1, Column: 22},
+		{Kind: Whitespace, Text: " ", Offset: 25, Line: 1, Column: 26},
+		{Kind: OptionalEnd, Text: "]]", Inside: "]]", Offset: 26, Line: 1, Column: 27},
+		{Kind: Whitespace, Text: " ", Offset: 28, Line: 1, Column: 29},
+		{Kind: Word, Text: "and", Offset: 29, Line: 1, Column: 30},
+		{Kind: Whitespace, Text: " ", Offset: 32, Line: 1, Column: 33},
+		{Kind: Word, Text: "a", Offset: 33, Line: 1, Column: 34},
+		{Kind: Punctuation, Text: "[", Offset: 34, Line: 1, Column: 35},
+		{Kind: Word, Text: "b", Offset: 35, Line: 1, Column: 36},
+		{Kind: Punctuation, Text: "[", Offset: 36, Line: 1, Column: 37},
+		{Kind: Word, Text: "1", Offset: 37, Line: 1, Column: 38},
+		{Kind: OptionalEnd, Text: "]]", Inside: "]]", Offset: 38, Line: 1, Column: 39}}
 	tokens := Lex(query)
 	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
 	if message != "" {
 		t.Error(message)
 	}
 }
+
+func TestLexerLexUnterminated(t *testing.T) {
+	// An unterminated string swallows the rest of the query, including things
+	// that look like parameters.
+	query := "select * /* a **/ from t where x = ? and y = 'it is ?\nand z = @z"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: Punctuation, Text: "*", Offset: 7, Line: 1, Column: 8},
+		{Kind: Whitespace, Text: " ", Offset: 8, Line: 1, Column: 9},
+		{Kind: Comment, Text: "/* a **/", Offset: 9, Line: 1, Column: 10},
+		{Kind: Whitespace, Text: " ", Offset: 17, Line: 1, Column: 18},
+		{Kind: Word, Text: "from", Offset: 18, Line: 1, Column: 19},
+		{Kind: Whitespace, Text: " ", Offset: 22, Line: 1, Column: 23},
+		{Kind: Word, Text: "t", Offset: 23, Line: 1, Column: 24},
+		{Kind: Whitespace, Text: " ", Offset: 24, Line: 1, Column: 25},
+		{Kind: Word, Text: "where", Offset: 25, Line: 1, Column: 26},
+		{Kind: Whitespace, Text: " ", Offset: 30, Line: 1, Column: 31},
+		{Kind: Word, Text: "x", Offset: 31, Line: 1, Column: 32},
+		{Kind: Whitespace, Text: " ", Offset: 32, Line: 1, Column: 33},
+		{Kind: Punctuation, Text: "=", Offset: 33, Line: 1, Column: 34},
+		{Kind: Whitespace, Text: " ", Offset: 34, Line: 1, Column: 35},
+		{Kind: ImplicitParameter, Text: "?", Inside: "?", Offset: 35, Line: 1, Column: 36},
+		{Kind: Whitespace, Text: " ", Offset: 36, Line: 1, Column: 37},
+		{Kind: Word, Text: "and", Offset: 37, Line: 1, Column: 38},
+		{Kind: Whitespace, Text: " ", Offset: 40, Line: 1, Column: 41},
+		{Kind: Word, Text: "y", Offset: 41, Line: 1, Column: 42},
+		{Kind: Whitespace, Text: " ", Offset: 42, Line: 1, Column: 43},
+		{Kind: Punctuation, Text: "=", Offset: 43, Line: 1, Column: 44},
+		{Kind: Whitespace, Text: " ", Offset: 44, Line: 1, Column: 45},
+		{Kind: Unterminated, Text: "'it is ?\nand z = @z", Inside: "'", Offset: 45, Line: 1, Column: 46}}
+	tokens := Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+
+	for _, query := range []string{"/* ?", "\"?", "`?", "'\\'?"} {
+		tokens := Lex(query)
+		if len(tokens) != 1 || tokens[0].Kind != Unterminated || tokens[0].Text != query {
+			t.Errorf("expected one unterminated token lexing %q, but got %v", query, tokens)
+		}
+	}
+}
+
+func TestLexerLexStandardStrings(t *testing.T) {
+	// With standard strings, a backslash doesn't escape, and quotes are
+	// escaped by doubling them.  Postgres's escape strings still escape.
+	query := `'C:\'?"a""b"'it''s'E'\'?'`
+	expected := []Token{
+		{Kind: String, Text: `'C:\'`, Offset: 0, Line: 1, Column: 1},
+		{Kind: ImplicitParameter, Text: "?", Inside: "?", Offset: 5, Line: 1, Column: 6},
+		{Kind: QuotedIdentifier, Text: `"a""b"`, Offset: 6, Line: 1, Column: 7},
+		{Kind: String, Text: "'it''s'", Offset: 12, Line: 1, Column: 13},
+		{Kind: String, Text: `E'\'?'`, Offset: 19, Line: 1, Column: 20}}
+	tokens := Dialect{StandardStrings: true}.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+
+	// Without standard strings, the backslash escapes the quote.
+	tokens = Lex(`'C:\'?`)
+	if len(tokens) != 1 || tokens[0].Kind != Unterminated {
+		t.Errorf("expected a single unterminated token, but got %v", tokens)
+	}
+}
+
+func TestLexerLexMySQL(t *testing.T) {
+	// System variables and "#" comments are not parameters.  User variables
+	// are lexed as named parameters, and it's up to arrange to decide.
+	query := "select @@session.sql_mode, @n := @n + 1 # it's ?\nfrom t"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: Word, Text: "@@session.sql_mode", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 25, Line: 1, Column: 26},
+		{Kind: Whitespace, Text: " ", Offset: 26, Line: 1, Column: 27},
+		{Kind: NamedParameter, Text: "@n", Inside: "n", Offset: 27, Line: 1, Column: 28},
+		{Kind: Whitespace, Text: " ", Offset: 29, Line: 1, Column: 30},
+		{Kind: Punctuation, Text: ":", Offset: 30, Line: 1, Column: 31},
+		{Kind: Punctuation, Text: "=", Offset: 31, Line: 1, Column: 32},
+		{Kind: Whitespace, Text: " ", Offset: 32, Line: 1, Column: 33},
+		{Kind: NamedParameter, Text: "@n", Inside: "n", Offset: 33, Line: 1, Column: 34},
+		{Kind: Whitespace, Text: " ", Offset: 35, Line: 1, Column: 36},
+		{Kind: Punctuation, Text: "+", Offset: 36, Line: 1, Column: 37},
+		{Kind: Whitespace, Text: " ", Offset: 37, Line: 1, Column: 38},
+		{Kind: Word, Text: "1", Offset: 38, Line: 1, Column: 39},
+		{Kind: Whitespace, Text: " ", Offset: 39, Line: 1, Column: 40},
+		{Kind: Comment, Text: "# it's ?\n", Offset: 40, Line: 1, Column: 41},
+		{Kind: Word, Text: "from", Offset: 49, Line: 2, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 53, Line: 2, Column: 5},
+		{Kind: Word, Text: "t", Offset: 54, Line: 2, Column: 6}}
+	tokens := MySQL.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexSQLServer(t *testing.T) {
+	// Bracketed identifiers, national strings, and system functions are not
+	// parameters, but "[[" still begins an optional fragment.
+	query := "select [a ]] @b], N'@c', @@rowcount from t where x = @x and y = [[@y]]"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: QuotedIdentifier, Text: "[a ]] @b]", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 16, Line: 1, Column: 17},
+		{Kind: Whitespace, Text: " ", Offset: 17, Line: 1, Column: 18},
+		{Kind: Word, Text: "N", Offset: 18, Line: 1, Column: 19},
+		{Kind: String, Text: "'@c'", Offset: 19, Line: 1, Column: 20},
+		{Kind: Punctuation, Text: ",", Offset: 23, Line: 1, Column: 24},
+		{Kind: Whitespace, Text: " ", Offset: 24, Line: 1, Column: 25},
+		{Kind: Word, Text: "@@rowcount", Offset: 25, Line: 1, Column: 26},
+		{Kind: Whitespace, Text: " ", Offset: 35, Line: 1, Column: 36},
+		{Kind: Word, Text: "from", Offset: 36, Line: 1, Column: 37},
+		{Kind: Whitespace, Text: " ", Offset: 40, Line: 1, Column: 41},
+		{Kind: Word, Text: "t", Offset: 41, Line: 1, Column: 42},
+		{Kind: Whitespace, Text: " ", Offset: 42, Line: 1, Column: 43},
+		{Kind: Word, Text: "where", Offset: 43, Line: 1, Column: 44},
+		{Kind: Whitespace, Text: " ", Offset: 48, Line: 1, Column: 49},
+		{Kind: Word, Text: "x", Offset: 49, Line: 1, Column: 50},
+		{Kind: Whitespace, Text: " ", Offset: 50, Line: 1, Column: 51},
+		{Kind: Punctuation, Text: "=", Offset: 51, Line: 1, Column: 52},
+		{Kind: Whitespace, Text: " ", Offset: 52, Line: 1, Column: 53},
+		{Kind: NamedParameter, Text: "@x", Inside: "x", Offset: 53, Line: 1, Column: 54},
+		{Kind: Whitespace, Text: " ", Offset: 55, Line: 1, Column: 56},
+		{Kind: Word, Text: "and", Offset: 56, Line: 1, Column: 57},
+		{Kind: Whitespace, Text: " ", Offset: 59, Line: 1, Column: 60},
+		{Kind: Word, Text: "y", Offset: 60, Line: 1, Column: 61},
+		{Kind: Whitespace, Text: " ", Offset: 61, Line: 1, Column: 62},
+		{Kind: Punctuation, Text: "=", Offset: 62, Line: 1, Column: 63},
+		{Kind: Whitespace, Text: " ", Offset: 63, Line: 1, Column: 64},
+		{Kind: OptionalBegin, Text: "[[", Inside: "[[", Offset: 64, Line: 1, Column: 65},
+		{Kind: NamedParameter, Text: "@y", Inside: "y", Offset: 66, Line: 1, Column: 67},
+		{Kind: OptionalEnd, Text: "]]", Inside: "]]", Offset: 68, Line: 1, Column: 69}}
+	tokens := SQLServer.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexQQuotes(t *testing.T) {
+	query := "select q'[it's @x]', Nq'!?!', q'(a)' || @y from t"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: String, Text: "q'[it's @x]'", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 19, Line: 1, Column: 20},
+		{Kind: Whitespace, Text: " ", Offset: 20, Line: 1, Column: 21},
+		{Kind: String, Text: "Nq'!?!'", Offset: 21, Line: 1, Column: 22},
+		{Kind: Punctuation, Text: ",", Offset: 28, Line: 1, Column: 29},
+		{Kind: Whitespace, Text: " ", Offset: 29, Line: 1, Column: 30},
+		{Kind: String, Text: "q'(a)'", Offset: 30, Line: 1, Column: 31},
+		{Kind: Whitespace, Text: " ", Offset: 36, Line: 1, Column: 37},
+		{Kind: Punctuation, Text: "|", Offset: 37, Line: 1, Column: 38},
+		{Kind: Punctuation, Text: "|", Offset: 38, Line: 1, Column: 39},
+		{Kind: Whitespace, Text: " ", Offset: 39, Line: 1, Column: 40},
+		{Kind: NamedParameter, Text: "@y", Inside: "y", Offset: 40, Line: 1, Column: 41},
+		{Kind: Whitespace, Text: " ", Offset: 42, Line: 1, Column: 43},
+		{Kind: Word, Text: "from", Offset: 43, Line: 1, Column: 44},
+		{Kind: Whitespace, Text: " ", Offset: 47, Line: 1, Column: 48},
+		{Kind: Word, Text: "t", Offset: 48, Line: 1, Column: 49}}
+	tokens := Oracle.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexDollarQuotes(t *testing.T) {
+	query := "select $$it's @x; $1$$, $body$ $$ @y $body$ || $1, a$b$c, $q$oops"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: String, Text: "$$it's @x; $1$$", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 22, Line: 1, Column: 23},
+		{Kind: Whitespace, Text: " ", Offset: 23, Line: 1, Column: 24},
+		{Kind: String, Text: "$body$ $$ @y $body$", Offset: 24, Line: 1, Column: 25},
+		{Kind: Whitespace, Text: " ", Offset: 43, Line: 1, Column: 44},
+		{Kind: Punctuation, Text: "|", Offset: 44, Line: 1, Column: 45},
+		{Kind: Punctuation, Text: "|", Offset: 45, Line: 1, Column: 46},
+		{Kind: Whitespace, Text: " ", Offset: 46, Line: 1, Column: 47},
+		{Kind: ExplicitParameter, Text: "$1", Inside: "1", Offset: 47, Line: 1, Column: 48},
+		{Kind: Punctuation, Text: ",", Offset: 49, Line: 1, Column: 50},
+		{Kind: Whitespace, Text: " ", Offset: 50, Line: 1, Column: 51},
+		{Kind: Word, Text: "a$b$c", Offset: 51, Line: 1, Column: 52},
+		{Kind: Punctuation, Text: ",", Offset: 56, Line: 1, Column: 57},
+		{Kind: Whitespace, Text: " ", Offset: 57, Line: 1, Column: 58},
+		{Kind: Unterminated, Text: "$q$oops", Inside: "$q$", Offset: 58, Line: 1, Column: 59}}
+	tokens := Postgres.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexSQLite(t *testing.T) {
+	query := "select ?2, $name, [a b], `c`, ?, $1 from t"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: ExplicitParameter, Text: "?2", Inside: "2", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 9, Line: 1, Column: 10},
+		{Kind: Whitespace, Text: " ", Offset: 10, Line: 1, Column: 11},
+		{Kind: NamedParameter, Text: "$name", Inside: "name", Offset: 11, Line: 1, Column: 12},
+		{Kind: Punctuation, Text: ",", Offset: 16, Line: 1, Column: 17},
+		{Kind: Whitespace, Text: " ", Offset: 17, Line: 1, Column: 18},
+		{Kind: QuotedIdentifier, Text: "[a b]", Offset: 18, Line: 1, Column: 19},
+		{Kind: Punctuation, Text: ",", Offset: 23, Line: 1, Column: 24},
+		{Kind: Whitespace, Text: " ", Offset: 24, Line: 1, Column: 25},
+		{Kind: QuotedIdentifier, Text: "`c`", Offset: 25, Line: 1, Column: 26},
+		{Kind: Punctuation, Text: ",", Offset: 28, Line: 1, Column: 29},
+		{Kind: Whitespace, Text: " ", Offset: 29, Line: 1, Column: 30},
+		{Kind: ImplicitParameter, Text: "?", Inside: "?", Offset: 30, Line: 1, Column: 31},
+		{Kind: Punctuation, Text: ",", Offset: 31, Line: 1, Column: 32},
+		{Kind: Whitespace, Text: " ", Offset: 32, Line: 1, Column: 33},
+		{Kind: ExplicitParameter, Text: "$1", Inside: "1", Offset: 33, Line: 1, Column: 34},
+		{Kind: Whitespace, Text: " ", Offset: 35, Line: 1, Column: 36},
+		{Kind: Word, Text: "from", Offset: 36, Line: 1, Column: 37},
+		{Kind: Whitespace, Text: " ", Offset: 40, Line: 1, Column: 41},
+		{Kind: Word, Text: "t", Offset: 41, Line: 1, Column: 42}}
+	tokens := SQLite.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexTyped(t *testing.T) {
+	query := "select {id:UInt64}, {names:Array(String)}, {} from t"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: NamedParameter, Text: "{id:UInt64}", Inside: "id", Type: "UInt64", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 18, Line: 1, Column: 19},
+		{Kind: Whitespace, Text: " ", Offset: 19, Line: 1, Column: 20},
+		{Kind: NamedParameter, Text: "{names:Array(String)}", Inside: "names", Type: "Array(String)", Offset: 20, Line: 1, Column: 21},
+		{Kind: Punctuation, Text: ",", Offset: 41, Line: 1, Column: 42},
+		{Kind: Whitespace, Text: " ", Offset: 42, Line: 1, Column: 43},
+		{Kind: Punctuation, Text: "{", Offset: 43, Line: 1, Column: 44},
+		{Kind: Punctuation, Text: "}", Offset: 44, Line: 1, Column: 45},
+		{Kind: Whitespace, Text: " ", Offset: 45, Line: 1, Column: 46},
+		{Kind: Word, Text: "from", Offset: 46, Line: 1, Column: 47},
+		{Kind: Whitespace, Text: " ", Offset: 50, Line: 1, Column: 51},
+		{Kind: Word, Text: "t", Offset: 51, Line: 1, Column: 52}}
+	tokens := Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexQuestionOperators(t *testing.T) {
+	query := "select * from t where data ?? 'a' and data ?| @keys and data ?& array['b'] and x = ?"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: Punctuation, Text: "*", Offset: 7, Line: 1, Column: 8},
+		{Kind: Whitespace, Text: " ", Offset: 8, Line: 1, Column: 9},
+		{Kind: Word, Text: "from", Offset: 9, Line: 1, Column: 10},
+		{Kind: Whitespace, Text: " ", Offset: 13, Line: 1, Column: 14},
+		{Kind: Word, Text: "t", Offset: 14, Line: 1, Column: 15},
+		{Kind: Whitespace, Text: " ", Offset: 15, Line: 1, Column: 16},
+		{Kind: Word, Text: "where", Offset: 16, Line: 1, Column: 17},
+		{Kind: Whitespace, Text: " ", Offset: 21, Line: 1, Column: 22},
+		{Kind: Word, Text: "data", Offset: 22, Line: 1, Column: 23},
+		{Kind: Whitespace, Text: " ", Offset: 26, Line: 1, Column: 27},
+		{Kind: Escape, Text: "??", Inside: "?", Offset: 27, Line: 1, Column: 28},
+		{Kind: Whitespace, Text: " ", Offset: 29, Line: 1, Column: 30},
+		{Kind: String, Text: "'a'", Offset: 30, Line: 1, Column: 31},
+		{Kind: Whitespace, Text: " ", Offset: 33, Line: 1, Column: 34},
+		{Kind: Word, Text: "and", Offset: 34, Line: 1, Column: 35},
+		{Kind: Whitespace, Text: " ", Offset: 37, Line: 1, Column: 38},
+		{Kind: Word, Text: "data", Offset: 38, Line: 1, Column: 39},
+		{Kind: Whitespace, Text: " ", Offset: 42, Line: 1, Column: 43},
+		{Kind: Punctuation, Text: "?|", Offset: 43, Line: 1, Column: 44},
+		{Kind: Whitespace, Text: " ", Offset: 45, Line: 1, Column: 46},
+		{Kind: NamedParameter, Text: "@keys", Inside: "keys", Offset: 46, Line: 1, Column: 47},
+		{Kind: Whitespace, Text: " ", Offset: 51, Line: 1, Column: 52},
+		{Kind: Word, Text: "and", Offset: 52, Line: 1, Column: 53},
+		{Kind: Whitespace, Text: " ", Offset: 55, Line: 1, Column: 56},
+		{Kind: Word, Text: "data", Offset: 56, Line: 1, Column: 57},
+		{Kind: Whitespace, Text: " ", Offset: 60, Line: 1, Column: 61},
+		{Kind: Punctuation, Text: "?&", Offset: 61, Line: 1, Column: 62},
+		{Kind: Whitespace, Text: " ", Offset: 63, Line: 1, Column: 64},
+		{Kind: Word, Text: "array", Offset: 64, Line: 1, Column: 65},
+		{Kind: Punctuation, Text: "[", Offset: 69, Line: 1, Column: 70},
+		{Kind: String, Text: "'b'", Offset: 70, Line: 1, Column: 71},
+		{Kind: Punctuation, Text: "]", Offset: 73, Line: 1, Column: 74},
+		{Kind: Whitespace, Text: " ", Offset: 74, Line: 1, Column: 75},
+		{Kind: Word, Text: "and", Offset: 75, Line: 1, Column: 76},
+		{Kind: Whitespace, Text: " ", Offset: 78, Line: 1, Column: 79},
+		{Kind: Word, Text: "x", Offset: 79, Line: 1, Column: 80},
+		{Kind: Whitespace, Text: " ", Offset: 80, Line: 1, Column: 81},
+		{Kind: Punctuation, Text: "=", Offset: 81, Line: 1, Column: 82},
+		{Kind: Whitespace, Text: " ", Offset: 82, Line: 1, Column: 83},
+		{Kind: ImplicitParameter, Text: "?", Inside: "?", Offset: 83, Line: 1, Column: 84}}
+	tokens := Postgres.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+
+	// In other dialects, "?|" is a parameter followed by "|".
+	tokens = Lex("a ?| b")
+	if len(tokens) != 6 || tokens[2].Kind != ImplicitParameter {
+		t.Errorf("expected an implicit parameter, but got %v", tokens)
+	}
+}
+
+func TestLexerLexPercent(t *testing.T) {
+	query := "select '%%', x %% 2 from t where a = %s and b = %(b)s and c = %sx"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: String, Text: "'%%'", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 11, Line: 1, Column: 12},
+		{Kind: Whitespace, Text: " ", Offset: 12, Line: 1, Column: 13},
+		{Kind: Word, Text: "x", Offset: 13, Line: 1, Column: 14},
+		{Kind: Whitespace, Text: " ", Offset: 14, Line: 1, Column: 15},
+		{Kind: Escape, Text: "%%", Inside: "%", Offset: 15, Line: 1, Column: 16},
+		{Kind: Whitespace, Text: " ", Offset: 17, Line: 1, Column: 18},
+		{Kind: Word, Text: "2", Offset: 18, Line: 1, Column: 19},
+		{Kind: Whitespace, Text: " ", Offset: 19, Line: 1, Column: 20},
+		{Kind: Word, Text: "from", Offset: 20, Line: 1, Column: 21},
+		{Kind: Whitespace, Text: " ", Offset: 24, Line: 1, Column: 25},
+		{Kind: Word, Text: "t", Offset: 25, Line: 1, Column: 26},
+		{Kind: Whitespace, Text: " ", Offset: 26, Line: 1, Column: 27},
+		{Kind: Word, Text: "where", Offset: 27, Line: 1, Column: 28},
+		{Kind: Whitespace, Text: " ", Offset: 32, Line: 1, Column: 33},
+		{Kind: Word, Text: "a", Offset: 33, Line: 1, Column: 34},
+		{Kind: Whitespace, Text: " ", Offset: 34, Line: 1, Column: 35},
+		{Kind: Punctuation, Text: "=", Offset: 35, Line: 1, Column: 36},
+		{Kind: Whitespace, Text: " ", Offset: 36, Line: 1, Column: 37},
+		{Kind: ImplicitParameter, Text: "%s", Inside: "%s", Offset: 37, Line: 1, Column: 38},
+		{Kind: Whitespace, Text: " ", Offset: 39, Line: 1, Column: 40},
+		{Kind: Word, Text: "and", Offset: 40, Line: 1, Column: 41},
+		{Kind: Whitespace, Text: " ", Offset: 43, Line: 1, Column: 44},
+		{Kind: Word, Text: "b", Offset: 44, Line: 1, Column: 45},
+		{Kind: Whitespace, Text: " ", Offset: 45, Line: 1, Column: 46},
+		{Kind: Punctuation, Text: "=", Offset: 46, Line: 1, Column: 47},
+		{Kind: Whitespace, Text: " ", Offset: 47, Line: 1, Column: 48},
+		{Kind: PythonParameter, Text: "%(b)s", Inside: "b", Offset: 48, Line: 1, Column: 49},
+		{Kind: Whitespace, Text: " ", Offset: 53, Line: 1, Column: 54},
+		{Kind: Word, Text: "and", Offset: 54, Line: 1, Column: 55},
+		{Kind: Whitespace, Text: " ", Offset: 57, Line: 1, Column: 58},
+		{Kind: Word, Text: "c", Offset: 58, Line: 1, Column: 59},
+		{Kind: Whitespace, Text: " ", Offset: 59, Line: 1, Column: 60},
+		{Kind: Punctuation, Text: "=", Offset: 60, Line: 1, Column: 61},
+		{Kind: Whitespace, Text: " ", Offset: 61, Line: 1, Column: 62},
+		{Kind: Punctuation, Text: "%", Offset: 62, Line: 1, Column: 63},
+		{Kind: Word, Text: "sx", Offset: 63, Line: 1, Column: 64}}
+	tokens := Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexEscapedSigils(t *testing.T) {
+	query := `select x::text, y \:foo, \@rownum := 1, \?, @z`
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: Word, Text: "x", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: "::", Offset: 8, Line: 1, Column: 9},
+		{Kind: Word, Text: "text", Offset: 10, Line: 1, Column: 11},
+		{Kind: Punctuation, Text: ",", Offset: 14, Line: 1, Column: 15},
+		{Kind: Whitespace, Text: " ", Offset: 15, Line: 1, Column: 16},
+		{Kind: Word, Text: "y", Offset: 16, Line: 1, Column: 17},
+		{Kind: Whitespace, Text: " ", Offset: 17, Line: 1, Column: 18},
+		{Kind: Escape, Text: `\:`, Inside: ":", Offset: 18, Line: 1, Column: 19},
+		{Kind: Word, Text: "foo", Offset: 20, Line: 1, Column: 21},
+		{Kind: Punctuation, Text: ",", Offset: 23, Line: 1, Column: 24},
+		{Kind: Whitespace, Text: " ", Offset: 24, Line: 1, Column: 25},
+		{Kind: Escape, Text: `\@`, Inside: "@", Offset: 25, Line: 1, Column: 26},
+		{Kind: Word, Text: "rownum", Offset: 27, Line: 1, Column: 28},
+		{Kind: Whitespace, Text: " ", Offset: 33, Line: 1, Column: 34},
+		{Kind: Punctuation, Text: ":", Offset: 34, Line: 1, Column: 35},
+		{Kind: Punctuation, Text: "=", Offset: 35, Line: 1, Column: 36},
+		{Kind: Whitespace, Text: " ", Offset: 36, Line: 1, Column: 37},
+		{Kind: Word, Text: "1", Offset: 37, Line: 1, Column: 38},
+		{Kind: Punctuation, Text: ",", Offset: 38, Line: 1, Column: 39},
+		{Kind: Whitespace, Text: " ", Offset: 39, Line: 1, Column: 40},
+		{Kind: Escape, Text: `\?`, Inside: "?", Offset: 40, Line: 1, Column: 41},
+		{Kind: Punctuation, Text: ",", Offset: 42, Line: 1, Column: 43},
+		{Kind: Whitespace, Text: " ", Offset: 43, Line: 1, Column: 44},
+		{Kind: NamedParameter, Text: "@z", Inside: "z", Offset: 44, Line: 1, Column: 45}}
+	tokens := Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexParameters(t *testing.T) {
+	query := "select :name, ?, @rownum, $1, %s, :2 from t"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: NamedParameter, Text: ":name", Inside: "name", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 12, Line: 1, Column: 13},
+		{Kind: Whitespace, Text: " ", Offset: 13, Line: 1, Column: 14},
+		{Kind: ImplicitParameter, Text: "?", Inside: "?", Offset: 14, Line: 1, Column: 15},
+		{Kind: Punctuation, Text: ",", Offset: 15, Line: 1, Column: 16},
+		{Kind: Whitespace, Text: " ", Offset: 16, Line: 1, Column: 17},
+		{Kind: Punctuation, Text: "@", Offset: 17, Line: 1, Column: 18},
+		{Kind: Word, Text: "rownum", Offset: 18, Line: 1, Column: 19},
+		{Kind: Punctuation, Text: ",", Offset: 24, Line: 1, Column: 25},
+		{Kind: Whitespace, Text: " ", Offset: 25, Line: 1, Column: 26},
+		{Kind: Punctuation, Text: "$", Offset: 26, Line: 1, Column: 27},
+		{Kind: Word, Text: "1", Offset: 27, Line: 1, Column: 28},
+		{Kind: Punctuation, Text: ",", Offset: 28, Line: 1, Column: 29},
+		{Kind: Whitespace, Text: " ", Offset: 29, Line: 1, Column: 30},
+		{Kind: Punctuation, Text: "%", Offset: 30, Line: 1, Column: 31},
+		{Kind: Word, Text: "s", Offset: 31, Line: 1, Column: 32},
+		{Kind: Punctuation, Text: ",", Offset: 32, Line: 1, Column: 33},
+		{Kind: Whitespace, Text: " ", Offset: 33, Line: 1, Column: 34},
+		{Kind: Punctuation, Text: ":", Offset: 34, Line: 1, Column: 35},
+		{Kind: Word, Text: "2", Offset: 35, Line: 1, Column: 36},
+		{Kind: Whitespace, Text: " ", Offset: 36, Line: 1, Column: 37},
+		{Kind: Word, Text: "from", Offset: 37, Line: 1, Column: 38},
+		{Kind: Whitespace, Text: " ", Offset: 41, Line: 1, Column: 42},
+		{Kind: Word, Text: "t", Offset: 42, Line: 1, Column: 43}}
+	tokens := Lexer{Parameters: ColonNameParameters | QuestionParameters}.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+
+	// The zero Lexer is like Lex.
+	query = "select :name, ?2, $name, {id:UInt64} from t"
+	message = tokensDisagreement(tokensCheck{actual: Lexer{}.Lex(query), expected: Lex(query)})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexQuotedNames(t *testing.T) {
+	query := "select @\"order \"\"id\"\"\", :`from`, :[weird-name], :[a]]b], [[x]] from t where y::\"char\" = :\"\""
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: NamedParameter, Text: `@"order ""id"""`, Inside: `order "id"`, Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 22, Line: 1, Column: 23},
+		{Kind: Whitespace, Text: " ", Offset: 23, Line: 1, Column: 24},
+		{Kind: NamedParameter, Text: ":`from`", Inside: "from", Offset: 24, Line: 1, Column: 25},
+		{Kind: Punctuation, Text: ",", Offset: 31, Line: 1, Column: 32},
+		{Kind: Whitespace, Text: " ", Offset: 32, Line: 1, Column: 33},
+		{Kind: NamedParameter, Text: ":[weird-name]", Inside: "weird-name", Offset: 33, Line: 1, Column: 34},
+		{Kind: Punctuation, Text: ",", Offset: 46, Line: 1, Column: 47},
+		{Kind: Whitespace, Text: " ", Offset: 47, Line: 1, Column: 48},
+		{Kind: NamedParameter, Text: ":[a]]b]", Inside: "a]b", Offset: 48, Line: 1, Column: 49},
+		{Kind: Punctuation, Text: ",", Offset: 55, Line: 1, Column: 56},
+		{Kind: Whitespace, Text: " ", Offset: 56, Line: 1, Column: 57},
+		{Kind: OptionalBegin, Text: "[[", Inside: "[[", Offset: 57, Line: 1, Column: 58},
+		{Kind: Word, Text: "x", Offset: 59, Line: 1, Column: 60},
+		{Kind: OptionalEnd, Text: "]]", Inside: "]]", Offset: 60, Line: 1, Column: 61},
+		{Kind: Whitespace, Text: " ", Offset: 62, Line: 1, Column: 63},
+		{Kind: Word, Text: "from", Offset: 63, Line: 1, Column: 64},
+		{Kind: Whitespace, Text: " ", Offset: 67, Line: 1, Column: 68},
+		{Kind: Word, Text: "t", Offset: 68, Line: 1, Column: 69},
+		{Kind: Whitespace, Text: " ", Offset: 69, Line: 1, Column: 70},
+		{Kind: Word, Text: "where", Offset: 70, Line: 1, Column: 71},
+		{Kind: Whitespace, Text: " ", Offset: 75, Line: 1, Column: 76},
+		{Kind: Word, Text: "y", Offset: 76, Line: 1, Column: 77},
+		{Kind: Punctuation, Text: "::", Offset: 77, Line: 1, Column: 78},
+		{Kind: QuotedIdentifier, Text: `"char"`, Offset: 79, Line: 1, Column: 80},
+		{Kind: Whitespace, Text: " ", Offset: 85, Line: 1, Column: 86},
+		{Kind: Punctuation, Text: "=", Offset: 86, Line: 1, Column: 87},
+		{Kind: Whitespace, Text: " ", Offset: 87, Line: 1, Column: 88},
+		{Kind: Punctuation, Text: ":", Offset: 88, Line: 1, Column: 89},
+		{Kind: QuotedIdentifier, Text: `""`, Offset: 89, Line: 1, Column: 90}}
+	tokens := Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestLexerLexPositions(t *testing.T) {
+	// Columns are counted in characters, and double quotes enclose strings
+	// in MySQL.
+	query := "select 'é',\n\t\"ü\" # ?\n  from t"
+	expected := []Token{
+		{Kind: Word, Text: "select", Offset: 0, Line: 1, Column: 1},
+		{Kind: Whitespace, Text: " ", Offset: 6, Line: 1, Column: 7},
+		{Kind: String, Text: "'é'", Offset: 7, Line: 1, Column: 8},
+		{Kind: Punctuation, Text: ",", Offset: 11, Line: 1, Column: 11},
+		{Kind: Whitespace, Text: "\n\t", Offset: 12, Line: 1, Column: 12},
+		{Kind: String, Text: `"ü"`, Offset: 14, Line: 2, Column: 2},
+		{Kind: Whitespace, Text: " ", Offset: 18, Line: 2, Column: 5},
+		{Kind: Comment, Text: "# ?\n", Offset: 19, Line: 2, Column: 6},
+		{Kind: Whitespace, Text: "  ", Offset: 23, Line: 3, Column: 1},
+		{Kind: Word, Text: "from", Offset: 25, Line: 3, Column: 3},
+		{Kind: Whitespace, Text: " ", Offset: 29, Line: 3, Column: 7},
+		{Kind: Word, Text: "t", Offset: 30, Line: 3, Column: 8}}
+	tokens := MySQL.Lex(query)
+	message := tokensDisagreement(tokensCheck{actual: tokens, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
@@ -0,0 +1,121 @@
+package namedsql
+
+import (
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parameterName returns a name for the parameter that token, an implicit
+// positional parameter output by arrange or expand, replaced: the name of a
+// named parameter, e.g. "color", or "p" followed by the index of an explicit
+// positional parameter, e.g. "p2".  A quoted name becomes an identifier, e.g.
+// "order_id" for "order id".  It returns "" if token replaced an implicit
+// positional parameter.
+func parameterName(token Token) string {
+	first, _ := utf8.DecodeRuneInString(token.Inside)
+	switch {
+	case unicode.IsDigit(first):
+		return "p" + identifierChars(token.Inside)
+	case unicode.IsLetter(first) || first == '_':
+		return identifierChars(token.Inside)
+	default:
+		return "" // e.g. "?" or "%s" as lexed, rather than output by arrange
+	}
+}
+
+// identifierChars returns name with each character that can't be part of an
+// identifier replaced by an underscore.
+func identifierChars(name string) string {
+	return strings.Map(func(char rune) rune {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' {
+			return char
+		}
+		return '_'
+	}, name)
+}
+
+// renderShared is like Render, except that implicit positional parameters are
+// written in the dialect's placeholder style, one of Named (e.g. ":color"),
+// QuestionNumber (e.g. "?3"), or Brace (e.g. "{p3:UInt64}"), and parameters
+// having the same name and the same binding share one output binding.  A
+// parameter without a name is named after its position, e.g. "p3".  If the
+// same name has different bindings, e.g. because it's used both in a query
+// and in a Fragment bound within the query, then the name is given a suffix,
+// e.g. "color_2".  Other tokens are written as by Dialect.outputText.  In the
+// Named and Brace styles, the output bindings are sql.NamedArg values.  In the
+// Brace style, it's an error for a parameter not to have a type.
+func (dialect Dialect) renderShared(tokens []Token, bindings []interface{}) (string, []interface{}, error) {
+	placeholder := dialect.Placeholder
+	texts := make([]string, len(tokens))
+	outputBindings := []interface{}{}
+	values := []interface{}{}
+	numbers := map[string]int{} // one-based index into values of each name
+	position := 0
+
+	for i, token := range tokens {
+		if token.Kind != ImplicitParameter {
+			texts[i] = dialect.outputText(token, false, false)
+			continue
+		}
+
+		if placeholder == Brace && token.Type == "" {
+			return "", nil, &MissingTypeError{Parameter: token.Text, Position: Position{Offset: token.Offset}}
+		}
+
+		binding := bindings[position]
+		position++
+		name := parameterName(token)
+		if name == "" {
+			name = "p" + strconv.Itoa(position)
+		}
+
+		unique := name
+		for suffix := 2; ; suffix++ {
+			number, exists := numbers[unique]
+			if !exists {
+				values = append(values, binding)
+				numbers[unique] = len(values)
+				switch placeholder {
+				case Named:
+					binding = sql.Named(unique, binding)
+				case Brace:
+					binding = sql.Named("p"+strconv.Itoa(len(values)), binding)
+				}
+				outputBindings = append(outputBindings, binding)
+				break
+			}
+			if reflect.DeepEqual(values[number-1], binding) {
+				break
+			}
+			unique = name + "_" + strconv.Itoa(suffix)
+		}
+
+		switch placeholder {
+		case Named:
+			texts[i] = ":" + unique
+		case Brace:
+			texts[i] = "{p" + strconv.Itoa(numbers[unique]) + ":" + token.Type + "}"
+		default:
+			texts[i] = placeholder.format(numbers[unique])
+		}
+	}
+
+	return strings.Join(texts, ""), outputBindings, nil
+}
+
+// NamedValues returns a map from the name of each sql.NamedArg in bindings to
+// its value, e.g. for the parameters of ClickHouse's HTTP interface.  Other
+// bindings are ignored.
+func NamedValues(bindings []interface{}) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, binding := range bindings {
+		if named, ok := binding.(sql.NamedArg); ok {
+			values[named.Name] = named.Value
+		}
+	}
+	return values
+}
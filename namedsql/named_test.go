@@ -0,0 +1,148 @@
+package namedsql
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNamedBreathing(t *testing.T) {
+	query := "select * from t where a = @a and b = %(b)s and c = :a and d in @ds and e = ?"
+	bindings := map[string]interface{}{"a": 1, "b": "two", "ds": []int{3, 4}}
+
+	actual, outputBindings, err := Options{Dialect: Oracle}.ArrangeAndExpand(query, bindings, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select * from t where a = :a and b = :b and c = :a and d in (:ds_1, :ds_2) and e = :p6"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	expectedBindings := []interface{}{
+		sql.Named("a", 1),
+		sql.Named("b", "two"),
+		sql.Named("ds_1", 3),
+		sql.Named("ds_2", 4),
+		sql.Named("p6", 5)}
+	message := sliceDisagreement(sliceCheck{actual: outputBindings, expected: expectedBindings})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestNamedClash(t *testing.T) {
+	// The fragment's "x" is not the query's "x".
+	fragment := NewFragment("x = @x", map[string]interface{}{"x": 2})
+	query := "select * from t where x = @x or @other"
+	bindings := map[string]interface{}{"x": 1, "other": fragment}
+
+	actual, outputBindings, err := Options{Dialect: Oracle}.Arrange(query, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select * from t where x = :x or x = :x_2"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	expectedBindings := []interface{}{sql.Named("x", 1), sql.Named("x_2", 2)}
+	message := sliceDisagreement(sliceCheck{actual: outputBindings, expected: expectedBindings})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestNamedQuestionNumber(t *testing.T) {
+	dialect := SQLite
+	dialect.Placeholder = QuestionNumber
+	query := "select * from t where a = $a and b = ?2 and c = @a and d in ?1 and e = ?2"
+	bindings := map[string]interface{}{"a": "x"}
+
+	actual, outputBindings, err := Options{Dialect: dialect}.ArrangeAndExpand(query, bindings, []int{1, 2}, "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select * from t where a = ?1 and b = ?2 and c = ?1 and d in (?3, ?4) and e = ?2"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	message := sliceDisagreement(sliceCheck{actual: outputBindings, expected: []interface{}{"x", "y", 1, 2}})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestNamedBrace(t *testing.T) {
+	query := "select * from t where id = {id:UInt64} and name in {names:String} and id != {id:UInt64}"
+	bindings := map[string]interface{}{"id": 7, "names": []string{"a", "b"}}
+
+	actual, outputBindings, err := Options{Dialect: ClickHouse}.ArrangeAndExpand(query, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select * from t where id = {p1:UInt64} and name in ({p2:String}, {p3:String}) and id != {p1:UInt64}"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	values := NamedValues(outputBindings)
+	expectedValues := map[string]interface{}{"p1": 7, "p2": "a", "p3": "b"}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("values not as expected.\nexpected: %v\nactual: %v", expectedValues, values)
+	}
+
+	// A list bound to an array parameter isn't expanded, since ClickHouse
+	// binds arrays as such.
+	actual, outputBindings, err = Options{Dialect: ClickHouse}.ArrangeAndExpand(
+		"select * from t where has({ids:Array(UInt64)}, id)", map[string]interface{}{"ids": []int{1, 2}})
+	expected = "select * from t where has({p1:Array(UInt64)}, id)"
+	if err != nil || actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q\nerror: %v", expected, actual, err)
+	}
+	values = NamedValues(outputBindings)
+	expectedValues = map[string]interface{}{"p1": []int{1, 2}}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("values not as expected.\nexpected: %v\nactual: %v", expectedValues, values)
+	}
+
+	// The same query can target other dialects, where types are ignored.
+	actual, _, err = Options{Dialect: MySQL}.Arrange(query, bindings)
+	expected = "select * from t where id = ? and name in ? and id != ?"
+	if err != nil || actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q\nerror: %v", expected, actual, err)
+	}
+
+	// Parameters without types can't be written as braces.
+	var missing *MissingTypeError
+	_, _, err = Options{Dialect: ClickHouse}.Arrange("select @id", bindings)
+	if !errors.As(err, &missing) || missing.Parameter != "@id" {
+		t.Errorf("expected a MissingTypeError for @id, but got: %v", err)
+	}
+	_, _, err = Options{Dialect: ClickHouse}.Expand("select ?", 1)
+	if !errors.As(err, &missing) || missing.Parameter != "?" {
+		t.Errorf("expected a MissingTypeError for ?, but got: %v", err)
+	}
+}
+
+func TestNamedQuotedNames(t *testing.T) {
+	query := `select * from report where "order id" = @"order id" and [weird-name] = :[weird-name]`
+	bindings := map[string]interface{}{"order id": 1, "weird-name": "x"}
+	actual, outputBindings, err := Options{Dialect: Oracle}.Arrange(query, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `select * from report where "order id" = :order_id and [weird-name] = :weird_name`
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	message := sliceDisagreement(sliceCheck{
+		actual:   outputBindings,
+		expected: []interface{}{sql.Named("order_id", 1), sql.Named("weird_name", "x")}})
+	if message != "" {
+		t.Error(message)
+	}
+}
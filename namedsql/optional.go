@@ -0,0 +1,116 @@
+package namedsql
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// fragment is an optional fragment of a query, i.e. the part between "[[" and
+// "]]", that is being collected by optional.
+type fragment struct {
+	begin  Token   // the "[[" that began the fragment
+	tokens []Token // the tokens in the fragment, not including nested "[[" and "]]"
+	bound  bool    // whether every parameter in the fragment is bound
+}
+
+// optional removes optional fragments from tokens.  An optional fragment is
+// the part of a query between "[[" and "]]", for example
+//
+//	select * from cars where 1 = 1 [[ and color = @color ]]
+//
+// If every named or explicit positional parameter in a fragment has a non-nil
+// binding, then the fragment is kept (without its brackets).  Otherwise, the
+// fragment is dropped.  Declared variables (see Dialect.LocalVariables) are
+// not parameters, and so don't affect whether a fragment is kept.  Within a
+// fragment, "@name" is a parameter even if it might be a user variable (see
+// Dialect.UserVariables), so that the fragment is dropped if name is unbound,
+// rather than kept with a variable that's probably NULL.  Fragments may be
+// nested, in which case each is kept or dropped on its own merits, except that
+// dropping a fragment drops the fragments nested within it.  A "]]" without a
+// matching "[[" is not special, and is kept as is.  Implicit positional
+// parameters are not allowed within fragments, because dropping one would
+// change the meaning of those after it.
+func (options Options) optional(tokens []Token, bindings map[string]interface{}, positionals ...interface{}) ([]Token, error) {
+	// fragments is a stack whose bottom element is the entire query.
+	fragments := []fragment{{tokens: make([]Token, 0, len(tokens))}}
+	declared := options.Dialect.declaredVariables(tokens)
+
+	for _, token := range tokens {
+		current := &fragments[len(fragments)-1]
+		switch token.Kind {
+		case OptionalBegin:
+			fragments = append(fragments, fragment{begin: token, bound: true})
+		case OptionalEnd:
+			if len(fragments) == 1 {
+				// not in a fragment, so it's just punctuation
+				token.Kind, token.Inside = Punctuation, ""
+				current.tokens = append(current.tokens, token)
+				continue
+			}
+			fragments = fragments[:len(fragments)-1]
+			if current.bound {
+				parent := &fragments[len(fragments)-1]
+				parent.tokens = append(parent.tokens, current.tokens...)
+			}
+		case ImplicitParameter:
+			if len(fragments) > 1 {
+				whine := &ImplicitInOptionalError{
+					Parameter: token.Text,
+					Position:  Position{Offset: token.Offset}}
+				return nil, whine
+			}
+			current.tokens = append(current.tokens, token)
+		case Unterminated:
+			// Report this before any unclosed fragment, since it's likely
+			// the reason that the fragment is unclosed.
+			whine := &UnterminatedError{
+				Delimiter: token.Inside,
+				Position:  Position{Offset: token.Offset}}
+			return nil, whine
+		case NamedParameter, PythonParameter:
+			dialect := options.Dialect
+			if len(fragments) > 1 {
+				dialect.UserVariables = false
+			}
+			if dialect.isVariable(token, bindings, declared) {
+				current.tokens = append(current.tokens, token)
+				continue
+			}
+			binding, ok := bindings[token.Inside]
+			current.bound = current.bound && ok && !isNil(binding)
+			current.tokens = append(current.tokens, token)
+		case ExplicitParameter:
+			i, _ := strconv.Atoi(token.Inside)
+			ok := i >= 1 && i <= len(positionals) && !isNil(positionals[i-1])
+			current.bound = current.bound && ok
+			current.tokens = append(current.tokens, token)
+		default:
+			current.tokens = append(current.tokens, token)
+		}
+	}
+
+	if len(fragments) != 1 {
+		begin := fragments[len(fragments)-1].begin
+		whine := &UnclosedOptionalError{
+			Delimiter: begin.Text,
+			Position:  Position{Offset: begin.Offset}}
+		return nil, whine
+	}
+
+	return fragments[0].tokens, nil
+}
+
+// isNil returns whether value is nil, either because it is a nil interface
+// or because it is a nil pointer, slice, map, channel, function, or
+// interface.
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	switch reflected := reflect.ValueOf(value); reflected.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+		return reflected.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package namedsql
+
+import "testing"
+
+func TestOptionalBreathing(t *testing.T) {
+	query := "select * from cars where 1=1[[ and color = @color]][[ and make = :make]]" +
+		"[[ and year = :1[[ and month = :2]]]] and a[b[1]]"
+	var nilPointer *int
+
+	cases := []struct {
+		bindings    map[string]interface{}
+		positionals []interface{}
+		expected    string
+	}{
+		{map[string]interface{}{"color": "red", "make": nil},
+			[]interface{}{2020},
+			"select * from cars where 1=1 and color = ? and year = ? and a[b[1]]"},
+		{map[string]interface{}{"make": "ford", "color": nilPointer},
+			[]interface{}{nil, 3},
+			"select * from cars where 1=1 and make = ? and a[b[1]]"},
+		{map[string]interface{}{},
+			[]interface{}{2020, 3},
+			"select * from cars where 1=1 and year = ? and month = ? and a[b[1]]"}}
+
+	for _, c := range cases {
+		actual, _, err := Arrange(query, c.bindings, c.positionals...)
+		if err != nil {
+			t.Error(err)
+		} else if actual != c.expected {
+			t.Errorf("query not as expected.\nexpected: %q\nactual: %q", c.expected, actual)
+		}
+	}
+}
+
+func TestOptionalUserVariables(t *testing.T) {
+	query := "select @rownum := @rownum + 1 from cars where 1=1 [[ and color = @color ]]"
+
+	cases := []struct {
+		bindings map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"color": "red"},
+			"select @rownum := @rownum + 1 from cars where 1=1  and color = ? "},
+		// An unbound "@color" is a parameter within the fragment, rather than
+		// a user variable, so the fragment is dropped.
+		{map[string]interface{}{},
+			"select @rownum := @rownum + 1 from cars where 1=1 "}}
+
+	for _, c := range cases {
+		actual, _, err := Options{Dialect: MySQL}.Arrange(query, c.bindings)
+		if err != nil {
+			t.Error(err)
+		} else if actual != c.expected {
+			t.Errorf("query not as expected.\nexpected: %q\nactual: %q", c.expected, actual)
+		}
+	}
+}
+
+func TestOptionalErrors(t *testing.T) {
+	for _, query := range []string{
+		"select 1 [[ and x = ? ]]",
+		"select 1 [[ and x = @x",
+		"select 1 [[ [[ and x = @x ]]"} {
+		_, _, err := Arrange(query, map[string]interface{}{"x": 1}, 2)
+		if err == nil {
+			t.Errorf("expected an error arranging %q", query)
+		}
+	}
+}
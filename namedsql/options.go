@@ -0,0 +1,196 @@
+package namedsql
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Options configures Arrange, Expand, and ArrangeAndExpand.  The zero value
+// of Options behaves like the package-level functions of the same names.
+type Options struct {
+	// Dialect determines the style of parameters in output queries.
+	Dialect Dialect
+
+	// Interpolate is whether bindings are replaced by literals in output
+	// queries, for use with connections that don't support prepared
+	// statements.  If Interpolate is true, the output bindings are always
+	// empty.  Bindings that have no literal in the dialect, such as the
+	// slices Arrange leaves for Expand, cause an error.
+	Interpolate bool
+
+	// AllErrors is whether every problem with the parameters of a query is
+	// reported, rather than only the first.  If AllErrors is true, the
+	// problems are combined using errors.Join.
+	AllErrors bool
+
+	// Strict is whether it's an error for a named binding or a positional
+	// binding not to be referred to by any parameter.
+	Strict bool
+
+	// AllowUnused are the names of bindings that may go unused even if Strict
+	// is true, e.g. because the bindings map is shared among queries.
+	AllowUnused []string
+
+	// redact, if not nil, matches the names of named parameters whose
+	// bindings arrange wraps as Secret, other than fragments and identifiers.
+	// Debugger uses it to redact bindings, including those of fragments.
+	redact *regexp.Regexp
+
+	// fragments identify the fragments being arranged, outermost first, so
+	// that a Fragment bound within itself is an error rather than endless
+	// recursion.
+	fragments []fragmentIdentity
+}
+
+// Arrange is like the package-level Arrange, but configured by options.
+func (options Options) Arrange(query string, bindings map[string]interface{}, positionals ...interface{}) (string, []interface{}, error) {
+	tokens, positionals, err := options.arrangeQuery(query, bindings, positionals...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return options.finish(query, tokens, positionals)
+}
+
+// Expand is like the package-level Expand, but configured by options.
+func (options Options) Expand(query string, bindings ...interface{}) (string, []interface{}, error) {
+	tokens := options.Dialect.Lex(query)
+	expandedTokens, expandedBindings, err := expand(tokens, bindings...)
+	if err != nil {
+		return "", nil, locate(err, query)
+	}
+
+	if err := options.strict(tokens, nil, bindings); err != nil {
+		return "", nil, err
+	}
+
+	return options.finish(query, expandedTokens, expandedBindings)
+}
+
+// ArrangeAndExpand is like the package-level ArrangeAndExpand, but configured
+// by options.
+func (options Options) ArrangeAndExpand(query string, bindings map[string]interface{}, positionals ...interface{}) (string, []interface{}, error) {
+	tokens, positionals, err := options.arrangeQuery(query, bindings, positionals...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tokens, positionals, err = expand(tokens, positionals...)
+	if err != nil {
+		return "", nil, locate(err, query)
+	}
+
+	return options.finish(query, tokens, positionals)
+}
+
+// arrangeQuery lexes query, removes its optional fragments, and arranges
+// it, checking for unused bindings if options.Strict is true.  Errors are
+// located within query.
+func (options Options) arrangeQuery(query string, bindings map[string]interface{}, positionals ...interface{}) ([]Token, []interface{}, error) {
+	tokens, outputBindings, err := options.arrangeTokens(options.Dialect.Lex(query), bindings, positionals...)
+	if err != nil {
+		return nil, nil, locate(err, query)
+	}
+
+	return tokens, outputBindings, nil
+}
+
+// arrangeTokens removes the optional fragments from the lexed tokens of a
+// query and arranges them, checking for unused bindings if options.Strict is
+// true.
+func (options Options) arrangeTokens(lexed []Token, bindings map[string]interface{}, positionals ...interface{}) ([]Token, []interface{}, error) {
+	tokens, err := options.optional(lexed, bindings, positionals...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, outputBindings, err := options.arrange(tokens, bindings, positionals...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := options.strict(lexed, bindings, positionals); err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, outputBindings, nil
+}
+
+// finish returns the query rendered from tokens and the output bindings,
+// having first interpolated bindings into tokens if options.Interpolate is
+// true.  Errors are located within query, the original query.
+func (options Options) finish(query string, tokens []Token, bindings []interface{}) (string, []interface{}, error) {
+	if !options.Interpolate {
+		switch options.Dialect.Placeholder {
+		case Named, QuestionNumber, Brace:
+			output, sharedBindings, err := options.Dialect.renderShared(tokens, bindings)
+			if err != nil {
+				return "", nil, locate(err, query)
+			}
+			return output, sharedBindings, nil
+		}
+		return options.render(tokens), bindings, nil
+	}
+
+	tokens, err := options.Dialect.interpolate(tokens, bindings)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// There are no parameters left, so nothing needs escaping.
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = options.Dialect.outputText(token, false, false)
+	}
+
+	return strings.Join(texts, ""), []interface{}{}, nil
+}
+
+// render is like Render, except that implicit positional parameters are
+// written in the style of the options' dialect, and other tokens are written
+// as by Dialect.outputText.  Escaped question marks are kept in the Question
+// style, so that the output can be lexed again, and percent signs are escaped
+// in the Percent style.
+func (options Options) render(tokens []Token) string {
+	placeholder := options.Dialect.Placeholder
+	texts := make([]string, len(tokens))
+	position := 0
+	for i, token := range tokens {
+		if token.Kind == ImplicitParameter {
+			position++
+			texts[i] = placeholder.format(position)
+		} else {
+			texts[i] = options.Dialect.outputText(token, placeholder == Question, placeholder == Percent)
+		}
+	}
+
+	return strings.Join(texts, "")
+}
+
+// outputText returns the text of token, which is not a parameter, as written
+// in an output query.  An escape, such as "??", is replaced by what it
+// escapes, unless it's an escaped question mark and keepQuestions is true, in
+// which case it's written as "??", even if it was written as "\?".
+// If escapePercents is true, then each literal "%" is escaped as "%%", as
+// required by the Percent style.  If dialect.PercentEscapes is true, then the
+// text of token is already escaped in that way, so it's unescaped if
+// escapePercents is false.  Literals written by interpolate are never
+// escaped.
+func (dialect Dialect) outputText(token Token, keepQuestions bool, escapePercents bool) string {
+	switch {
+	case token.Kind == Literal:
+		return token.Text
+	case token.Kind == Escape && token.Inside == "?" && keepQuestions:
+		return "??"
+	case token.Kind == Escape && escapePercents:
+		return strings.ReplaceAll(token.Inside, "%", "%%")
+	case token.Kind == Escape:
+		return token.Inside
+	case escapePercents && !dialect.PercentEscapes:
+		return strings.ReplaceAll(token.Text, "%", "%%")
+	case !escapePercents && dialect.PercentEscapes:
+		return strings.ReplaceAll(token.Text, "%%", "%")
+	default:
+		return token.Text
+	}
+}
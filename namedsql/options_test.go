@@ -0,0 +1,216 @@
+package namedsql
+
+import "testing"
+
+func TestOptionsPlaceholders(t *testing.T) {
+	query := "select * from t where x in @xs and y = ? limit :1"
+	bindings := map[string]interface{}{"xs": []int{1, 2}}
+
+	cases := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{Dialect{}, "select * from t where x in (?, ?) and y = ? limit ?"},
+		{Generic, "select * from t where x in (?, ?) and y = ? limit ?"},
+		{Postgres, "select * from t where x in ($1, $2) and y = $3 limit $4"},
+		{Dialect{Placeholder: Colon}, "select * from t where x in (:1, :2) and y = :3 limit :4"},
+		{SQLServer, "select * from t where x in (@p1, @p2) and y = @p3 limit @p4"}}
+
+	for _, c := range cases {
+		actual, outputBindings, err := Options{Dialect: c.dialect}.ArrangeAndExpand(query, bindings, "foo")
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("query not as expected.\nexpected: %q\nactual: %q", c.expected, actual)
+		}
+		expectedBindings := []interface{}{1, 2, "foo", "foo"}
+		message := sliceDisagreement(sliceCheck{actual: outputBindings, expected: expectedBindings})
+		if message != "" {
+			t.Error(message)
+		}
+	}
+}
+
+func TestLookupDialect(t *testing.T) {
+	for _, name := range Dialects() {
+		dialect, err := LookupDialect(name)
+		if err != nil {
+			t.Error(err)
+		} else if dialect.Name != name {
+			t.Errorf("looked up dialect %q but got %q", name, dialect.Name)
+		}
+	}
+
+	if _, err := LookupDialect("nonsense"); err == nil {
+		t.Error("expected an error looking up an unknown dialect")
+	}
+
+	for _, placeholder := range []Placeholder{Question, Dollar, Colon, AtP, Named, QuestionNumber, Brace, Percent} {
+		parsed, err := ParsePlaceholder(placeholder.String())
+		if err != nil || parsed != placeholder {
+			t.Errorf("round trip of placeholder %v failed: %v, %v", placeholder, parsed, err)
+		}
+	}
+}
+
+func TestOptionsStandardStrings(t *testing.T) {
+	query, bindings, err := Options{Dialect: Postgres}.Arrange(`select * from t where path = 'C:\' and x = @x`,
+		map[string]interface{}{"x": 1})
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	expectedQuery := `select * from t where path = 'C:\' and x = $1`
+	if query != expectedQuery {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expectedQuery, query)
+	}
+
+	message := sliceDisagreement(sliceCheck{actual: bindings, expected: []interface{}{1}})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestOptionsUserVariables(t *testing.T) {
+	query := "select @@sql_mode, @rownum := @rownum + 1, x from t where x > @min # @min?"
+	bindings := map[string]interface{}{"min": 10}
+
+	actual, outputBindings, err := Options{Dialect: MySQL}.Arrange(query, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "select @@sql_mode, @rownum := @rownum + 1, x from t where x > ? # @min?"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	message := sliceDisagreement(sliceCheck{actual: outputBindings, expected: []interface{}{10}})
+	if message != "" {
+		t.Error(message)
+	}
+
+	// With user variables turned off, every "@name" is a parameter.
+	dialect := MySQL
+	dialect.UserVariables = false
+	if _, _, err := (Options{Dialect: dialect}).Arrange(query, bindings); err == nil {
+		t.Error("expected an error for the unbound parameter @rownum")
+	}
+}
+
+func TestOptionsEscape(t *testing.T) {
+	query := "select * from t where data ?? @key and data ?| @keys"
+	bindings := map[string]interface{}{"key": "a", "keys": "{b,c}"}
+
+	cases := []struct {
+		options  Options
+		expected string
+	}{
+		{Options{Dialect: Postgres}, "select * from t where data ? $1 and data ?| $2"},
+		{Options{Dialect: Postgres, Interpolate: true}, "select * from t where data ? 'a' and data ?| '{b,c}'"},
+		// The question mark style keeps the escape, so that the output can be
+		// expanded.
+		{Options{Dialect: Dialect{QuestionOperators: true}}, "select * from t where data ?? ? and data ?| ?"}}
+
+	for _, c := range cases {
+		actual, _, err := c.options.Arrange(query, bindings)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("query not as expected.\nexpected: %q\nactual: %q", c.expected, actual)
+		}
+	}
+
+	// In the Question style, a backslash-escaped question mark is written as
+	// "??", without the backslash.
+	actual, _, err := Arrange(`select a \? b, c ?? d from t where x = @x`, map[string]interface{}{"x": 1})
+	expected := "select a ?? b, c ?? d from t where x = ?"
+	if err != nil || actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q\nerror: %v", expected, actual, err)
+	}
+}
+
+func TestOptionsPercent(t *testing.T) {
+	cases := []struct {
+		dialect  Dialect
+		query    string
+		expected string
+	}{
+		// Queries written for Python keep their escapes in the Percent style,
+		// and lose them otherwise.
+		{Dialect{PercentEscapes: true, Placeholder: Percent},
+			"select * from t where name like 'abc%%' and x %% 2 = @x",
+			"select * from t where name like 'abc%%' and x %% 2 = %s"},
+		{Dialect{PercentEscapes: true, Placeholder: Dollar},
+			"select * from t where name like 'abc%%' and x %% 2 = %s",
+			"select * from t where name like 'abc%' and x % 2 = $1"},
+		// Other queries gain escapes in the Percent style.
+		{Dialect{Placeholder: Percent},
+			"select * from t where name like 'abc%' and x % 2 = %(x)s",
+			"select * from t where name like 'abc%%' and x %% 2 = %s"},
+		{Dialect{},
+			"select * from t where name like 'abc%%' and x %% 2 = %s",
+			"select * from t where name like 'abc%%' and x % 2 = ?"}}
+
+	for _, c := range cases {
+		actual, bindings, err := Options{Dialect: c.dialect}.Arrange(c.query, map[string]interface{}{"x": 1}, 1)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if actual != c.expected {
+			t.Errorf("query not as expected.\nexpected: %q\nactual: %q", c.expected, actual)
+		}
+		message := sliceDisagreement(sliceCheck{actual: bindings, expected: []interface{}{1}})
+		if message != "" {
+			t.Error(message)
+		}
+	}
+
+	// Interpolated literals are not escaped, since there are no parameters.
+	actual, _, err := Options{Dialect: Dialect{PercentEscapes: true}, Interpolate: true}.Arrange(
+		"select 'a%%' || @x", map[string]interface{}{"x": "b%%"})
+	expected := "select 'a%' || 'b%%'"
+	if err != nil || actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q\nerror: %v", expected, actual, err)
+	}
+}
+
+func TestOptionsEscapedSigils(t *testing.T) {
+	query := `select data -> 'a' \:foo, x::text, \@n := \@n + 1 from t where y = @y`
+	actual, bindings, err := Options{Dialect: Postgres}.Arrange(query, map[string]interface{}{"y": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select data -> 'a' :foo, x::text, @n := @n + 1 from t where y = $1"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	message := sliceDisagreement(sliceCheck{actual: bindings, expected: []interface{}{1}})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestOptionsLexer(t *testing.T) {
+	dialect := MySQL
+	dialect.Lexer = Lexer{Parameters: ColonNameParameters}
+	query := "select @rownum := @rownum + 1 from t where id = :id"
+	actual, bindings, err := Options{Dialect: dialect}.Arrange(query, map[string]interface{}{"id": 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "select @rownum := @rownum + 1 from t where id = ?"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	message := sliceDisagreement(sliceCheck{actual: bindings, expected: []interface{}{7}})
+	if message != "" {
+		t.Error(message)
+	}
+}
@@ -0,0 +1,92 @@
+package namedsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parameter describes an occurrence of a parameter in a query.
+type Parameter struct {
+	// Kind is the kind of the parameter's Token: ImplicitParameter,
+	// ExplicitParameter, NamedParameter, or PythonParameter.
+	Kind Kind
+
+	// Text is the full text of the parameter, e.g. "@color" or "$2".
+	Text string
+
+	// Name is the name of a named or python parameter, e.g. "color", and is
+	// empty for positional parameters.
+	Name string
+
+	// Index is the one-based index of the positional binding that a positional
+	// parameter refers to, and is zero for named parameters.  For an implicit
+	// positional parameter, this is its position among the implicit
+	// positional parameters in the query.
+	Index int
+
+	// Type is the type annotation of a typed parameter, e.g. "UInt64" in
+	// "{id:UInt64}", and is empty for other parameters.
+	Type string
+
+	// Offset is the zero-based byte offset of the parameter in the query.
+	Offset int
+
+	// In is whether the parameter immediately follows the keyword "in", as in
+	// "where id in @ids", and so is expected to be bound to a list.
+	In bool
+}
+
+// Parameters returns a description of each occurrence of a parameter in
+// query, in order, and the distinct names of the named parameters, in order of
+// their first occurrence.
+func Parameters(query string) ([]Parameter, []string) {
+	return Options{}.Parameters(query)
+}
+
+// Parameters is like the package-level Parameters, but configured by options.
+// Local variables declared in query (see Dialect.LocalVariables) are not
+// parameters.
+func (options Options) Parameters(query string) ([]Parameter, []string) {
+	parameters := []Parameter{}
+	names := []string{}
+	seen := map[string]bool{}
+	implicitCount := 0
+
+	// previous is the text of the most recent token that is not whitespace or
+	// a comment, for determining whether a parameter follows "in".
+	previous := ""
+
+	tokens := options.Dialect.Lex(query)
+	declared := options.Dialect.declaredVariables(tokens)
+	for _, token := range tokens {
+		parameter := Parameter{Kind: token.Kind, Text: token.Text, Type: token.Type, Offset: token.Offset}
+		switch token.Kind {
+		case ImplicitParameter:
+			implicitCount++
+			parameter.Index = implicitCount
+		case ExplicitParameter:
+			parameter.Index, _ = strconv.Atoi(token.Inside)
+		case NamedParameter, PythonParameter:
+			if strings.HasPrefix(token.Text, "@") && declared[strings.ToLower(token.Inside)] {
+				previous = token.Text
+				continue // local variable
+			}
+			parameter.Name = token.Inside
+			if !seen[token.Inside] {
+				seen[token.Inside] = true
+				names = append(names, token.Inside)
+			}
+		case Whitespace, Comment:
+			continue
+		default:
+			previous = token.Text
+			continue
+		}
+
+		parameter.In = strings.EqualFold(previous, "in")
+		parameters = append(parameters, parameter)
+		previous = token.Text
+	}
+
+	return parameters, names
+}
@@ -0,0 +1,40 @@
+package namedsql
+
+import "testing"
+
+func TestParametersBreathing(t *testing.T) {
+	query := "select * from t where a IN @as and b = :b and c not in /* list */ ? " +
+		"and d in (@b, $2) and e = %(e)s and f = ? -- in @nope\n" +
+		"and pin = @b and g in\n\t:1"
+	parameters, names := Parameters(query)
+
+	expected := []Parameter{
+		{Kind: NamedParameter, Text: "@as", Name: "as", Offset: 27, In: true},
+		{Kind: NamedParameter, Text: ":b", Name: "b", Offset: 39},
+		{Kind: ImplicitParameter, Text: "?", Index: 1, Offset: 66, In: true},
+		{Kind: NamedParameter, Text: "@b", Name: "b", Offset: 78},
+		{Kind: ExplicitParameter, Text: "$2", Index: 2, Offset: 82},
+		{Kind: PythonParameter, Text: "%(e)s", Name: "e", Offset: 94},
+		{Kind: ImplicitParameter, Text: "?", Index: 2, Offset: 108},
+		{Kind: NamedParameter, Text: "@b", Name: "b", Offset: 132},
+		{Kind: ExplicitParameter, Text: ":1", Index: 1, Offset: 145, In: true}}
+
+	if len(parameters) != len(expected) {
+		t.Fatalf("expected %d parameters, but got %d: %+v", len(expected), len(parameters), parameters)
+	}
+	for i, parameter := range parameters {
+		if parameter != expected[i] {
+			t.Errorf("parameter %d not as expected.\nexpected: %+v\nactual: %+v", i, expected[i], parameter)
+		}
+	}
+
+	expectedNames := []string{"as", "b", "e"}
+	if len(names) != len(expectedNames) {
+		t.Fatalf("names not as expected.\nexpected: %q\nactual: %q", expectedNames, names)
+	}
+	for i, name := range names {
+		if name != expectedNames[i] {
+			t.Errorf("names not as expected.\nexpected: %q\nactual: %q", expectedNames, names)
+		}
+	}
+}
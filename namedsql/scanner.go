@@ -0,0 +1,132 @@
+package namedsql
+
+import (
+	"io"
+	"regexp"
+)
+
+// scannerBufferSize is the minimum number of bytes that a Scanner reads at a
+// time.
+const scannerBufferSize = 64 * 1024
+
+// Scanner lexes tokens from an io.Reader, so that a large script needn't be
+// held in memory all at once.  The tokens returned by successive calls to Next
+// are the same as those that Lex would return for the entire text read,
+// including their offsets, lines, and columns.
+type Scanner struct {
+	reader io.Reader
+	// streamingRegexp lexes text that might be continued, and finalRegexp
+	// lexes the text that remains once reader is exhausted.
+	streamingRegexp *regexp.Regexp
+	finalRegexp     *regexp.Regexp
+
+	buffer []byte
+	// pending is text read but not yet returned as tokens, since its tokens
+	// might depend on text not yet read.  offset, line, and column are the
+	// position of pending within the text read.
+	pending string
+	offset  int
+	line    int
+	column  int
+
+	// tokens are lexed but not yet returned by Next.
+	tokens []Token
+	// err is the error to return once tokens are exhausted, e.g. io.EOF.
+	err error
+}
+
+// NewScanner returns a Scanner that lexes tokens from reader according to
+// the conventions of the Generic dialect.
+func NewScanner(reader io.Reader) *Scanner {
+	return Generic.NewScanner(reader)
+}
+
+// NewScanner returns a Scanner that lexes tokens from reader, recognizing only
+// the parameter syntaxes selected by lexer.  Otherwise, the Scanner follows the
+// conventions of the Generic dialect.
+func (lexer Lexer) NewScanner(reader io.Reader) *Scanner {
+	return Dialect{Lexer: lexer}.NewScanner(reader)
+}
+
+// NewScanner returns a Scanner that lexes tokens from reader according to the
+// conventions of the dialect.
+func (dialect Dialect) NewScanner(reader io.Reader) *Scanner {
+	return &Scanner{
+		reader:          reader,
+		streamingRegexp: tokenRegexp(dialect.syntax(true)),
+		finalRegexp:     tokenRegexp(dialect.syntax(false)),
+		line:            1,
+		column:          1}
+}
+
+// Next returns the next token read, or returns io.EOF once every token has
+// been returned.  If reading fails, then Next returns the tokens that
+// preceded the failure and then returns the error.
+func (scanner *Scanner) Next() (Token, error) {
+	for len(scanner.tokens) == 0 {
+		if scanner.err != nil {
+			return Token{}, scanner.err
+		}
+		scanner.read()
+	}
+
+	token := scanner.tokens[0]
+	scanner.tokens = scanner.tokens[1:]
+	return token, nil
+}
+
+// read reads more of the text and lexes what it can.  A token is known to be
+// complete if whitespace follows it, since every pattern that could continue
+// through whitespace instead matches as "unterminated" through the end of the
+// text (see tokenPatterns).  So, the last whitespace and everything after it
+// remain pending.
+func (scanner *Scanner) read() {
+	size := scannerBufferSize
+	if len(scanner.pending) > size {
+		size = len(scanner.pending)
+	}
+	if len(scanner.buffer) < size {
+		scanner.buffer = make([]byte, size)
+	}
+
+	count, err := scanner.reader.Read(scanner.buffer[:size])
+	scanner.pending += string(scanner.buffer[:count])
+
+	switch {
+	case err == io.EOF:
+		tokens := lex(scanner.finalRegexp, scanner.pending)
+		scanner.locate(tokens)
+		scanner.tokens = append(scanner.tokens, tokens...)
+		scanner.pending = ""
+		scanner.err = io.EOF
+	case err != nil:
+		scanner.pending = ""
+		scanner.err = err
+	case count != 0:
+		tokens := lex(scanner.streamingRegexp, scanner.pending)
+		last := len(tokens) - 1
+		for last >= 0 && tokens[last].Kind != Whitespace {
+			last--
+		}
+		if last <= 0 {
+			return // nothing is known to be complete yet
+		}
+		held := tokens[last].Offset
+		scanner.locate(tokens)
+		scanner.tokens = append(scanner.tokens, tokens[:last]...)
+		scanner.pending = scanner.pending[held:]
+		scanner.offset, scanner.line, scanner.column = tokens[last].Offset, tokens[last].Line, tokens[last].Column
+	}
+}
+
+// locate adjusts the position of each of tokens, which are lexed from the
+// pending text, to be its position within the entire text read.
+func (scanner *Scanner) locate(tokens []Token) {
+	for i := range tokens {
+		if tokens[i].Line == 1 {
+			tokens[i].Column += scanner.column - 1
+		}
+		tokens[i].Line += scanner.line - 1
+		tokens[i].Offset += scanner.offset
+	}
+}
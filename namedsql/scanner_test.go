@@ -0,0 +1,203 @@
+package namedsql
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// scanAll returns the tokens returned by scanner, and the error that ended
+// them, or nil if that error was io.EOF.
+func scanAll(scanner *Scanner) ([]Token, error) {
+	tokens := []Token{}
+	for {
+		token, err := scanner.Next()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestScannerBreathing(t *testing.T) {
+	query := "select * from t where x = @x and y in ($1, :2)"
+	actual, err := scanAll(NewScanner(strings.NewReader(query)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := tokensDisagreement(tokensCheck{actual: actual, expected: Lex(query)})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestScannerEmpty(t *testing.T) {
+	actual, err := scanAll(NewScanner(strings.NewReader("")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := tokensDisagreement(tokensCheck{actual: actual, expected: []Token{}})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+// TestScannerSplitTokens reads queries a little at a time, so that tokens are
+// divided among reads, and checks that the tokens are the same as those from
+// Lex.
+func TestScannerSplitTokens(t *testing.T) {
+	cases := []struct {
+		dialect Dialect
+		query   string
+	}{
+		{Generic, "select 'it''s a string', \"quoted name\" -- comment here\nfrom t where x = @x"},
+		{Generic, "select /* a block\ncomment */ x,\n\ty from t where z = :z"},
+		{Generic, "select 'unterminated string with spaces"},
+		{Generic, "select * from t where x = @\"order id\" and y = :[weird name] and z = $`a b`"},
+		{Generic, "select * from t [[where x = @x]] and y = %(y)s and z = %s"},
+		{Generic, "select 'héllo wörld', ünïcödé　x from t"},
+		{Postgres, `select E'it\'s a \\ string', 'C:\' from t where x = $1`},
+		{Postgres, "select * from t where data ?? @key and data ?| @keys"},
+		{Postgres, "create function f() returns int as $body$ begin return 1; end $body$ language plpgsql; select $$ a $$"},
+		{MySQL, `select "a string", @@sql_mode, @rownum := @rownum + 1 # note here` + "\nfrom t"},
+		{SQLServer, "select [column name], [a ]] b] from t where x = @x\nGO\nselect 1"},
+		{Oracle, "select q'[it's a string]', nq'{another one}' from t where x = :x"},
+		{SQLite, "select * from t where x = ?1 and y = $name and z = ?"},
+		{ClickHouse, "select * from t where x = {x: UInt64} and y = {y : Array(String)}"}}
+
+	readers := []struct {
+		name string
+		wrap func(io.Reader) io.Reader
+	}{
+		{"whole", func(reader io.Reader) io.Reader { return reader }},
+		{"one byte", iotest.OneByteReader},
+		{"half", iotest.HalfReader}}
+
+	for _, c := range cases {
+		expected := c.dialect.Lex(c.query)
+		for _, reader := range readers {
+			actual, err := scanAll(c.dialect.NewScanner(reader.wrap(strings.NewReader(c.query))))
+			if err != nil {
+				t.Errorf("%s reader: %v", reader.name, err)
+				continue
+			}
+			message := tokensDisagreement(tokensCheck{actual: actual, expected: expected})
+			if message != "" {
+				t.Errorf("%s reader, query %q: %s", reader.name, c.query, message)
+			}
+		}
+	}
+}
+
+// TestScannerLarge reads a script longer than a Scanner's buffer, containing a
+// string that's longer still.
+func TestScannerLarge(t *testing.T) {
+	script := strings.Repeat("select * from t where x = @x; -- comment\n", 2000) +
+		"select '" + strings.Repeat("a long string ", 10000) + "';\n"
+	actual, err := scanAll(NewScanner(strings.NewReader(script)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := tokensDisagreement(tokensCheck{actual: actual, expected: Lex(script)})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestScannerLexer(t *testing.T) {
+	lexer := Lexer{Parameters: ColonNameParameters}
+	query := "select @rownum from t where id = :id"
+	actual, err := scanAll(lexer.NewScanner(iotest.OneByteReader(strings.NewReader(query))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := tokensDisagreement(tokensCheck{actual: actual, expected: lexer.Lex(query)})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+// countingReader is an io.Reader that counts the bytes read from reader.
+type countingReader struct {
+	reader io.Reader
+	count  int
+}
+
+func (counter *countingReader) Read(buffer []byte) (int, error) {
+	count, err := counter.reader.Read(buffer)
+	counter.count += count
+	return count, err
+}
+
+// TestScannerBounded checks that a Scanner returns the first tokens of a large
+// script after reading only some of it, including in dialects whose closed
+// strings resemble the beginning of unclosed ones.
+func TestScannerBounded(t *testing.T) {
+	cases := []struct {
+		dialect Dialect
+		first   string
+	}{
+		{Generic, "select 'x' from dual;\n"},
+		{Oracle, "select q'[x]' from dual;\n"},
+		{Postgres, "select E'x', $$x$$ from dual;\n"}}
+
+	for _, c := range cases {
+		script := c.first + strings.Repeat("select * from t where x = :x;\n", 200000)
+		counter := &countingReader{reader: strings.NewReader(script)}
+		scanner := c.dialect.NewScanner(counter)
+		for i := 0; i < 20; i++ {
+			if _, err := scanner.Next(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if counter.count > 2*scannerBufferSize {
+			t.Errorf("dialect %s read %d of %d bytes for the first 20 tokens",
+				c.dialect.Name, counter.count, len(script))
+		}
+	}
+}
+
+func TestScannerError(t *testing.T) {
+	failure := errors.New("disk on fire")
+	reader := io.MultiReader(strings.NewReader("select x from 'unfinished"), iotest.ErrReader(failure))
+	actual, err := scanAll(NewScanner(reader))
+	if err != failure {
+		t.Errorf("error not as expected.\nexpected: %v\nactual: %v", failure, err)
+	}
+
+	// The tokens before the last whitespace are known to be complete, so
+	// they're returned before the error.
+	expected := Lex("select x from")
+	message := tokensDisagreement(tokensCheck{actual: actual, expected: expected})
+	if message != "" {
+		t.Error(message)
+	}
+}
+
+func TestScannerManyDollarQuotes(t *testing.T) {
+	script := strings.Repeat("create function f() returns int as $$ select 1; $$ language sql;\n", 5000)
+
+	start := time.Now()
+	actual, err := scanAll(Postgres.NewScanner(strings.NewReader(script)))
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("scanning %d bytes took %v", len(script), elapsed)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := tokensDisagreement(tokensCheck{actual: actual, expected: Postgres.Lex(script)})
+	if message != "" {
+		t.Error(message)
+	}
+}
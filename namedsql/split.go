@@ -0,0 +1,172 @@
+package namedsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Statement is one of the statements of a script, as separated by semicolons.
+type Statement struct {
+	// Query is the text of the statement, without its terminating semicolon
+	// or the whitespace around it.
+	Query string
+
+	// Bindings are the output bindings of Query.  Dialect.Split leaves
+	// Bindings empty.
+	Bindings []interface{}
+
+	// Offset is the zero-based byte offset of the statement in the script.
+	Offset int
+}
+
+// Split splits script into statements.  Split follows the conventions of the
+// Generic dialect, except that strings can also be dollar-quoted, as in
+// Postgres, so that a function body such as "$$ select 1; $$" isn't split.
+// See Dialect.Split.
+func Split(script string) []Statement {
+	dialect := Generic
+	dialect.DollarQuotes = true
+	return dialect.Split(script)
+}
+
+// Split splits script into statements.  Each semicolon separates two
+// statements, unless it's within a string (including a dollar-quoted string,
+// if dialect.DollarQuotes is true), quoted identifier, or comment, or within a
+// block that begins with "BEGIN" or "CASE" and ends with "END", e.g.
+// the body of a trigger.  "BEGIN" followed by a semicolon, or by a word such
+// as "TRANSACTION", begins a transaction rather than a block.  If
+// dialect.GoBatches is true, then a "GO" line also separates two statements
+// (see Batches).  Statements that contain only whitespace and comments are
+// omitted.
+func (dialect Dialect) Split(script string) []Statement {
+	statements := []Statement{}
+	for _, tokens := range dialect.statements(dialect.Lex(script)) {
+		statements = append(statements, Statement{Query: Render(tokens), Bindings: []interface{}{}, Offset: tokens[0].Offset})
+	}
+	return statements
+}
+
+// ArrangeScript splits script into statements as by Split, and arranges each
+// statement as by Arrange.  Every statement shares the same bindings.
+func ArrangeScript(script string, bindings map[string]interface{}) ([]Statement, error) {
+	return Options{Dialect: Dialect{DollarQuotes: true}}.ArrangeScript(script, bindings)
+}
+
+// ArrangeScript is like the package-level ArrangeScript, but configured by
+// options.  If options.Strict is true, then it's an error for a binding not
+// to be referred to by any statement.  The positions of errors are relative
+// to script.
+func (options Options) ArrangeScript(script string, bindings map[string]interface{}) ([]Statement, error) {
+	lexed := options.Dialect.Lex(script)
+
+	// A binding need only be used by one of the statements, so check the
+	// whole script afterward, rather than each statement.
+	strict := options.Strict
+	options.Strict = false
+
+	statements := []Statement{}
+	for i, statementTokens := range options.Dialect.statements(lexed) {
+		tokens, outputBindings, err := options.arrangeTokens(statementTokens, bindings)
+		if err != nil {
+			return nil, fmt.Errorf("in statement %d: %w", i+1, locate(err, script))
+		}
+
+		query, outputBindings, err := options.finish(script, tokens, outputBindings)
+		if err != nil {
+			return nil, fmt.Errorf("in statement %d: %w", i+1, err)
+		}
+
+		statements = append(statements, Statement{Query: query, Bindings: outputBindings, Offset: statementTokens[0].Offset})
+	}
+
+	options.Strict = strict
+	if err := options.strict(lexed, bindings, nil); err != nil {
+		return nil, err
+	}
+
+	return statements, nil
+}
+
+// statements divides the lexed tokens of a script into the tokens of each of
+// its statements, as described for Dialect.Split.
+func (dialect Dialect) statements(tokens []Token) [][]Token {
+	statements := [][]Token{}
+
+	// finishStatement appends the tokens of a statement to statements,
+	// without the whitespace around them, unless there's nothing but
+	// whitespace and comments.
+	finishStatement := func(current []Token) {
+		for len(current) != 0 && current[0].Kind == Whitespace {
+			current = current[1:]
+		}
+		for len(current) != 0 && current[len(current)-1].Kind == Whitespace {
+			current = current[:len(current)-1]
+		}
+		for _, token := range current {
+			if token.Kind != Comment {
+				statements = append(statements, current)
+				break
+			}
+		}
+	}
+
+	for _, batch := range dialect.batches(tokens) {
+		// depth is the number of blocks, such as "BEGIN ... END", that
+		// enclose the current token.
+		depth := 0
+		begin := 0
+		for i, token := range batch {
+			switch {
+			case token.Kind == Punctuation && token.Text == ";" && depth == 0:
+				finishStatement(batch[begin:i])
+				begin = i + 1
+			case token.Kind != Word:
+				// It can't begin or end a block.
+			case strings.EqualFold(token.Text, "begin") && beginsBlock(batch, i),
+				strings.EqualFold(token.Text, "case") && !strings.EqualFold(adjacentText(batch, i, -1), "end"):
+				depth++
+			case strings.EqualFold(token.Text, "end") && depth > 0 && endsBlock(batch, i):
+				depth--
+			}
+		}
+		finishStatement(batch[begin:])
+	}
+
+	return statements
+}
+
+// beginsBlock returns whether tokens[i], the word "BEGIN", begins a block,
+// rather than a transaction, e.g. "BEGIN;" or "BEGIN TRANSACTION".
+func beginsBlock(tokens []Token, i int) bool {
+	switch strings.ToLower(adjacentText(tokens, i, 1)) {
+	case "", ";", "transaction", "tran", "work", "deferred", "immediate", "exclusive",
+		"isolation", "read", "distributed":
+		return false
+	default:
+		return true
+	}
+}
+
+// endsBlock returns whether tokens[i], the word "END", ends a block begun by
+// "BEGIN" or "CASE", rather than, e.g., "END IF" or "END LOOP", whose
+// beginnings aren't counted.
+func endsBlock(tokens []Token, i int) bool {
+	switch strings.ToLower(adjacentText(tokens, i, 1)) {
+	case "if", "loop", "while", "repeat", "for":
+		return false
+	default:
+		return true
+	}
+}
+
+// adjacentText returns the text of the token nearest to tokens[i], in the
+// direction of step (1 for after, or -1 for before), that isn't whitespace
+// or a comment.  It returns "" if there is no such token.
+func adjacentText(tokens []Token, i int, step int) string {
+	for j := i + step; j >= 0 && j < len(tokens); j += step {
+		if tokens[j].Kind != Whitespace && tokens[j].Kind != Comment {
+			return tokens[j].Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package namedsql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkStatements reports any difference between the queries and offsets of
+// actual and those of expected.
+func checkStatements(t *testing.T, actual []Statement, expected []Statement) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d statements but got %d: %q", len(expected), len(actual), actual)
+	}
+	for i := range expected {
+		if actual[i].Query != expected[i].Query || actual[i].Offset != expected[i].Offset {
+			t.Errorf("statement %d not as expected.\nexpected: %q\nactual: %q", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestSplitBreathing(t *testing.T) {
+	script := "create table t (x int);\ninsert into t values (';'); -- a comment; really\n\nselect * from t;\n"
+	expected := []Statement{
+		{Query: "create table t (x int)", Offset: 0},
+		{Query: "insert into t values (';')", Offset: 24},
+		{Query: "-- a comment; really\n\nselect * from t", Offset: 52}}
+
+	checkStatements(t, Split(script), expected)
+}
+
+func TestSplitEmpty(t *testing.T) {
+	for _, script := range []string{"", " \n", ";;", "/* nothing */;", "-- nothing\n"} {
+		if actual := Split(script); len(actual) != 0 {
+			t.Errorf("expected no statements in %q, but got %q", script, actual)
+		}
+	}
+
+	// A trailing comment isn't a statement of its own.
+	if actual := Split("select 1; -- done\n"); len(actual) != 1 || actual[0].Query != "select 1" {
+		t.Errorf("expected only the statement %q, but got %q", "select 1", actual)
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	script := `begin transaction;
+create trigger tr after insert on t
+begin
+  update u set n = case when n > 0 then n + 1 else 1 end;
+  delete from v;
+end;
+begin;
+commit;
+create procedure p() begin
+  if x then
+    select 1;
+  end if;
+  case x when 1 then select 2; end case;
+end`
+	expected := []Statement{
+		{Query: "begin transaction", Offset: 0},
+		{Query: "create trigger tr after insert on t\nbegin\n  update u set n = case when n > 0 then n + 1 else 1 end;\n" +
+			"  delete from v;\nend", Offset: 19},
+		{Query: "begin", Offset: 141},
+		{Query: "commit", Offset: 148},
+		{Query: "create procedure p() begin\n  if x then\n    select 1;\n  end if;\n" +
+			"  case x when 1 then select 2; end case;\nend", Offset: 156}}
+
+	checkStatements(t, MySQL.Split(script), expected)
+}
+
+func TestSplitDollarQuotes(t *testing.T) {
+	script := "create function f() returns int as $$ select 1; $$ language sql;\n" +
+		"do $body$ begin perform 1; end $body$;"
+	expected := []Statement{
+		{Query: "create function f() returns int as $$ select 1; $$ language sql", Offset: 0},
+		{Query: "do $body$ begin perform 1; end $body$", Offset: 65}}
+
+	checkStatements(t, Postgres.Split(script), expected)
+
+	// The package-level Split recognizes dollar quotes too.
+	checkStatements(t, Split(script), expected)
+
+	// Other dialects don't have dollar quotes.
+	expectedQuery := "create function f() returns int as $$ select 1"
+	if actual := Generic.Split(script); actual[0].Query != expectedQuery {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expectedQuery, actual[0].Query)
+	}
+}
+
+func TestSplitBatches(t *testing.T) {
+	script := "select 1\nGO\nselect 2; select 3\n"
+	expected := []Statement{
+		{Query: "select 1", Offset: 0},
+		{Query: "select 2", Offset: 12},
+		{Query: "select 3", Offset: 22}}
+
+	checkStatements(t, SQLServer.Split(script), expected)
+}
+
+func TestArrangeScript(t *testing.T) {
+	script := "insert into t values (@x, @y);\nselect * from t where x = :x and z = @y;"
+	bindings := map[string]interface{}{"x": 1, "y": 2}
+
+	statements, err := ArrangeScript(script, bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Statement{
+		{Query: "insert into t values (?, ?)", Bindings: []interface{}{1, 2}, Offset: 0},
+		{Query: "select * from t where x = ? and z = ?", Bindings: []interface{}{1, 2}, Offset: 31}}
+	checkStatements(t, statements, expected)
+	for i, statement := range statements {
+		if i == len(expected) {
+			break
+		}
+		message := sliceDisagreement(sliceCheck{actual: statement.Bindings, expected: expected[i].Bindings})
+		if message != "" {
+			t.Error(message)
+		}
+	}
+
+	// Like Split, ArrangeScript recognizes dollar quotes.
+	statements, err = ArrangeScript("create function f() returns int as $$ select 1; $$ language sql; select @x", bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 2 {
+		t.Errorf("expected 2 statements but got %d: %q", len(statements), statements)
+	}
+}
+
+func TestArrangeScriptErrors(t *testing.T) {
+	script := "select @x;\nselect @y;"
+
+	_, err := ArrangeScript(script, map[string]interface{}{"x": 1})
+	if err == nil || !strings.Contains(err.Error(), "in statement 2") {
+		t.Errorf("expected an error in statement 2, but got: %v", err)
+	}
+
+	// Each binding need only be used by one of the statements.
+	bindings := map[string]interface{}{"x": 1, "y": 2}
+	if _, err := (Options{Strict: true}).ArrangeScript(script, bindings); err != nil {
+		t.Error(err)
+	}
+
+	bindings["z"] = 3
+	if _, err := (Options{Strict: true}).ArrangeScript(script, bindings); err == nil {
+		t.Error("expected an error for the unused binding z")
+	}
+}
+
+// TestSplitManyDollarQuotes checks that a script with many dollar-quoted
+// strings is split in reasonable time, since lexing each one begins matching
+// again after it.
+func TestSplitManyDollarQuotes(t *testing.T) {
+	script := strings.Repeat("create function f() returns int as $$ select 1; $$ language sql;\n", 5000)
+
+	start := time.Now()
+	statements := Postgres.Split(script)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("splitting %d bytes took %v", len(script), elapsed)
+	}
+	if len(statements) != 5000 {
+		t.Errorf("expected 5000 statements but got %d", len(statements))
+	}
+}
@@ -0,0 +1,87 @@
+package namedsql
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// strict returns an error if options.Strict is true and some of bindings or
+// positionals are not referred to by any parameter among tokens.  Names in
+// options.AllowUnused are exempt.  Unless options.AllErrors is true, only the
+// first problem is returned.  Parameters in optional fragments count as
+// references even if the fragment was dropped, so tokens should be as lexed.
+func (options Options) strict(tokens []Token, bindings map[string]interface{}, positionals []interface{}) error {
+	if !options.Strict {
+		return nil
+	}
+
+	problems := unusedBindings(tokens, bindings, options.AllowUnused)
+	problems = append(problems, unusedPositionals(tokens, len(positionals))...)
+	if len(problems) == 0 {
+		return nil
+	}
+	if !options.AllErrors {
+		return problems[0]
+	}
+	return errors.Join(problems...)
+}
+
+// unusedBindings returns an *UnusedBindingError for each name in bindings,
+// in sorted order, that is neither referred to by a named parameter among
+// tokens nor in allowed.
+func unusedBindings(tokens []Token, bindings map[string]interface{}, allowed []string) []error {
+	used := make(map[string]bool, len(bindings))
+	for _, name := range allowed {
+		used[name] = true
+	}
+	for _, token := range tokens {
+		if token.Kind == NamedParameter || token.Kind == PythonParameter {
+			used[token.Inside] = true
+		}
+	}
+
+	var names []string
+	for name := range bindings {
+		if !used[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	problems := make([]error, len(names))
+	for i, name := range names {
+		problems[i] = &UnusedBindingError{Name: name}
+	}
+	return problems
+}
+
+// unusedPositionals returns an *UnusedPositionalError for each of count
+// positional bindings that is neither consumed by an implicit positional
+// parameter among tokens nor referred to by an explicit positional parameter
+// among tokens.
+func unusedPositionals(tokens []Token, count int) []error {
+	used := make([]bool, count)
+	implicitCount := 0
+	for _, token := range tokens {
+		switch token.Kind {
+		case ImplicitParameter:
+			if implicitCount < count {
+				used[implicitCount] = true
+			}
+			implicitCount++
+		case ExplicitParameter:
+			if i, _ := strconv.Atoi(token.Inside); i >= 1 && i <= count {
+				used[i-1] = true
+			}
+		}
+	}
+
+	var problems []error
+	for i, isUsed := range used {
+		if !isUsed {
+			problems = append(problems, &UnusedPositionalError{Index: i + 1})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,56 @@
+package namedsql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictBreathing(t *testing.T) {
+	bindings := map[string]interface{}{"a": 1, "typo": 2, "shared": 3, "zzz": 4}
+	options := Options{Strict: true, AllowUnused: []string{"shared"}}
+
+	_, _, err := options.Arrange("select @a, ?", bindings, 1, 2)
+	var unusedBinding *UnusedBindingError
+	if !errors.As(err, &unusedBinding) || unusedBinding.Name != "typo" {
+		t.Errorf("expected an unused binding error for typo, but got %v", err)
+	}
+
+	options.AllErrors = true
+	_, _, err = options.ArrangeAndExpand("select @a, ?", bindings, 1, 2)
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 3 {
+		t.Fatalf("expected three errors, but got %v", err)
+	}
+	var unusedPositional *UnusedPositionalError
+	if !errors.As(joined.Unwrap()[2], &unusedPositional) || unusedPositional.Index != 2 {
+		t.Errorf("expected an unused positional error for 2, but got %v", joined.Unwrap()[2])
+	}
+
+	// Bindings in dropped optional fragments count as used.
+	_, _, err = options.Arrange("select @a [[, @typo]] [[, @zzz]] [[, :1]]",
+		map[string]interface{}{"a": 1, "typo": nil, "zzz": 4}, nil)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStrictExpand(t *testing.T) {
+	options := Options{Strict: true}
+	if _, _, err := options.Expand("select ?", 1); err != nil {
+		t.Error(err)
+	}
+
+	_, _, err := options.Expand("select ?", 1, 2)
+	var unused *UnusedPositionalError
+	if !errors.As(err, &unused) || unused.Index != 2 {
+		t.Errorf("expected an unused positional error for 2, but got %v", err)
+	}
+
+	// Strict applies within fragments, too.
+	fragment := NewFragment("x = @x", map[string]interface{}{"x": 1, "y": 2})
+	_, _, err = options.Arrange("select @f", map[string]interface{}{"f": fragment})
+	var unusedBinding *UnusedBindingError
+	if !errors.As(err, &unusedBinding) || unusedBinding.Name != "y" {
+		t.Errorf("expected an unused binding error for y, but got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package namedsql
+
+import "errors"
+
+// Validate returns every problem that Arrange would have with query and its
+// bindings, as well as a problem for each positional binding that is not
+// referred to by any parameter.  The problems are combined using errors.Join,
+// so each can be inspected using errors.As.  Validate returns nil if there are
+// no problems.
+func Validate(query string, bindings map[string]interface{}, positionals ...interface{}) error {
+	return Options{}.Validate(query, bindings, positionals...)
+}
+
+// Validate is like the package-level Validate, but configured by options.
+// Validate reports every problem regardless of options.AllErrors.
+func (options Options) Validate(query string, bindings map[string]interface{}, positionals ...interface{}) error {
+	options.AllErrors = true
+	tokens := options.Dialect.Lex(query)
+	optionalTokens, err := options.optional(tokens, bindings, positionals...)
+	if err != nil {
+		return locate(err, query)
+	}
+
+	var problems []error
+	if _, _, err := options.arrange(optionalTokens, bindings, positionals...); err != nil {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			problems = append(problems, joined.Unwrap()...)
+		} else {
+			problems = append(problems, err)
+		}
+	}
+
+	// Explicit positional parameters in dropped optional fragments count as
+	// references, so look at all of the tokens.
+	problems = append(problems, unusedPositionals(tokens, len(positionals))...)
+
+	return locate(errors.Join(problems...), query)
+}
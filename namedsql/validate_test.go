@@ -0,0 +1,69 @@
+package namedsql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateBreathing(t *testing.T) {
+	err := Validate(
+		"select * from t where a = @a and b = @b and c = :0 and d = :5 and e = ? and f = ? and g = ?",
+		map[string]interface{}{"a": 1},
+		1, 2, 3, 4)
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, but got %v", err)
+	}
+
+	problems := joined.Unwrap()
+	if len(problems) != 4 {
+		t.Fatalf("expected 4 problems, but got %d: %v", len(problems), err)
+	}
+
+	var missing *MissingBindingError
+	if !errors.As(problems[0], &missing) || missing.Name != "b" || missing.Column != 38 {
+		t.Errorf("expected a missing binding for b, but got %v", problems[0])
+	}
+	var badIndex *BadIndexError
+	if !errors.As(problems[1], &badIndex) || badIndex.Index != 0 {
+		t.Errorf("expected a bad index 0, but got %v", problems[1])
+	}
+	if !errors.As(problems[2], &badIndex) || badIndex.Index != 5 {
+		t.Errorf("expected a bad index 5, but got %v", problems[2])
+	}
+	var unused *UnusedPositionalError
+	if !errors.As(problems[3], &unused) || unused.Index != 4 {
+		t.Errorf("expected an unused positional 4, but got %v", problems[3])
+	}
+}
+
+func TestValidateMissingPositionals(t *testing.T) {
+	err := Validate("select ?, ?, ?", nil, 1)
+
+	var missing *MissingPositionalError
+	if !errors.As(err, &missing) || missing.Index != 2 {
+		t.Errorf("expected a missing positional 2, but got %v", err)
+	}
+	if joined := err.(interface{ Unwrap() []error }); len(joined.Unwrap()) != 2 {
+		t.Errorf("expected two problems, but got %v", err)
+	}
+
+	if err := Validate("select :1, @a", map[string]interface{}{"a": 2}, 1); err != nil {
+		t.Errorf("expected no problems, but got %v", err)
+	}
+}
+
+func TestAllErrors(t *testing.T) {
+	query := "select @a, @b, @c"
+	_, _, err := Arrange(query, nil)
+	if _, joined := err.(interface{ Unwrap() []error }); joined {
+		t.Errorf("expected only the first error by default, but got %v", err)
+	}
+
+	_, _, err = Options{AllErrors: true}.Arrange(query, nil)
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 3 {
+		t.Errorf("expected three errors, but got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package namedsql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isVariable returns whether token is a variable, rather than a named
+// parameter, given bindings and the local variables declared in the query
+// (as returned by declaredVariables).
+func (dialect Dialect) isVariable(token Token, bindings map[string]interface{}, declared map[string]bool) bool {
+	if token.Kind != NamedParameter || !strings.HasPrefix(token.Text, "@") {
+		return false
+	}
+	if declared[strings.ToLower(token.Inside)] {
+		return true
+	}
+	if !dialect.UserVariables {
+		return false
+	}
+	_, bound := bindings[token.Inside]
+	return !bound
+}
+
+// declaredVariables returns the names, in lower case, of the local variables
+// declared in tokens, e.g. "x" and "y" in "DECLARE @x int, @y varchar(10)".
+// It returns nil unless dialect.LocalVariables is true.
+func (dialect Dialect) declaredVariables(tokens []Token) map[string]bool {
+	if !dialect.LocalVariables {
+		return nil
+	}
+
+	declared := map[string]bool{}
+
+	// Within a DECLARE statement, a variable is expected after "DECLARE" and
+	// after each comma outside of parentheses.  The statement ends at a
+	// semicolon, or at a line break that is neither preceded nor followed by
+	// a comma.
+	var (
+		declaring bool // whether we're within a DECLARE statement
+		expecting bool // whether the next "@name" is a declared variable
+		broken    bool // whether a line break might have ended the statement
+		depth     int  // parenthesis depth within the DECLARE statement
+		last      rune // the last non-space character in the statement
+	)
+
+	for _, token := range tokens {
+		if token.Kind == NamedParameter && expecting && strings.HasPrefix(token.Text, "@") {
+			declared[strings.ToLower(token.Inside)] = true
+			expecting, broken, last = false, false, '@'
+			continue
+		}
+
+		// Scan the text of the token, or of something equivalent to it:
+		// comments are whitespace, and anything else that isn't plain text
+		// (e.g. a string or a parameter in a default value) is a single
+		// non-space character.
+		text := token.Text
+		switch {
+		case token.Kind == Comment && strings.HasPrefix(text, "/*"):
+			text = " "
+		case token.Kind == Comment:
+			text = "\n"
+		case !token.Kind.isText():
+			text = "x"
+		}
+
+		for _, char := range text {
+			if !declaring {
+				break
+			}
+			switch {
+			case char == '\n':
+				broken = broken || last != ','
+				continue
+			case unicode.IsSpace(char):
+				continue
+			case broken && char != ',':
+				declaring = false
+				continue
+			case char == '(':
+				depth++
+			case char == ')':
+				depth--
+			case char == ';':
+				declaring = false
+			}
+			expecting = char == ',' && depth == 0
+			broken = false
+			last = char
+		}
+
+		if token.Kind == Word && strings.EqualFold(text, "declare") {
+			declaring, expecting, broken, depth, last = true, true, false, 0, 0
+		}
+	}
+
+	return declared
+}
@@ -0,0 +1,51 @@
+package namedsql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeclaredVariables(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected map[string]bool
+	}{
+		{"declare @x int, @Y decimal(10, 2) = @z; select @a, @b",
+			map[string]bool{"x": true, "y": true}},
+		{"DECLARE @x int\nSELECT @x, @y",
+			map[string]bool{"x": true}},
+		{"DECLARE @x int,\n    @y int -- the y\n  , @z int\nselect @x, @w",
+			map[string]bool{"x": true, "y": true, "z": true}},
+		{"declare @t table (a int, b varchar(10)); insert into @t values (@a, @b)",
+			map[string]bool{"t": true}},
+		{"select 'declare @x', undeclare @y",
+			map[string]bool{}}}
+
+	for _, c := range cases {
+		actual := SQLServer.declaredVariables(SQLServer.Lex(c.query))
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("declared variables of %q not as expected.\nexpected: %v\nactual: %v", c.query, c.expected, actual)
+		}
+	}
+
+	if actual := Generic.declaredVariables(Generic.Lex("declare @x int")); actual != nil {
+		t.Errorf("expected no declared variables in the generic dialect, but got %v", actual)
+	}
+}
+
+func TestOptionsLocalVariables(t *testing.T) {
+	query := "declare @total int = 0; select @total = sum(x) from t where y = @y; select @@rowcount, @total"
+	actual, bindings, err := Options{Dialect: SQLServer}.Arrange(query, map[string]interface{}{"y": 1, "total": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "declare @total int = 0; select @total = sum(x) from t where y = @p1; select @@rowcount, @total"
+	if actual != expected {
+		t.Errorf("query not as expected.\nexpected: %q\nactual: %q", expected, actual)
+	}
+	message := sliceDisagreement(sliceCheck{actual: bindings, expected: []interface{}{1}})
+	if message != "" {
+		t.Error(message)
+	}
+}